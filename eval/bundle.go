@@ -0,0 +1,170 @@
+package eval
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// bundleIndex maps the identifiers a reference might use — a fullUrl
+// (including "urn:uuid:...") or a "ResourceType/id" pair — to the raw
+// JSON of the matching Bundle.entry.resource.
+type bundleIndex map[string][]byte
+
+// containedIndex maps a contained resource's "#id" fragment to its raw
+// JSON.
+type containedIndex map[string][]byte
+
+// WithBundle returns a copy of c that resolves references locally
+// against entry.fullUrl / entry.resource in the given transaction or
+// document Bundle before falling back to the external Resolver.
+//
+// Malformed bundleJSON is not an error here: it simply yields no local
+// matches, so resolution falls through to the Resolver exactly as if
+// WithBundle had not been called.
+func (c *Context) WithBundle(bundleJSON []byte) *Context {
+	cp := c.clone()
+	cp.bundle = indexBundle(bundleJSON)
+	return cp
+}
+
+// WithContainedRoot returns a copy of c that resolves "#id" references
+// against the `contained` array of resourceJSON before falling back to
+// the Bundle or the external Resolver. Expressions evaluated via
+// Evaluate([]byte) use the evaluated resource as the contained root
+// automatically; this is for evaluating against a different root (for
+// example a Bundle entry while c.resource is the enclosing Bundle).
+func (c *Context) WithContainedRoot(resourceJSON []byte) *Context {
+	cp := c.clone()
+	cp.contained = indexContained(resourceJSON)
+	return cp
+}
+
+// ResolveLocal looks up reference against the contained resources and
+// Bundle in scope, in that order, and reports whether a match was
+// found. It never touches the external Resolver.
+func (c *Context) ResolveLocal(reference string) ([]byte, bool) {
+	if reference == "" {
+		return nil, false
+	}
+
+	if strings.HasPrefix(reference, "#") {
+		if c.contained == nil {
+			return nil, false
+		}
+		raw, ok := c.contained[strings.TrimPrefix(reference, "#")]
+		return raw, ok
+	}
+
+	if c.bundle == nil {
+		return nil, false
+	}
+
+	if raw, ok := c.bundle[reference]; ok {
+		return raw, ok
+	}
+
+	// A versioned reference ("Patient/123/_history/2") resolves to the
+	// unversioned entry; the spec treats resolve() as returning the
+	// current contents of the reference, not a specific version. An
+	// absolute URL may additionally need reducing to its trailing
+	// "ResourceType/id" to match how the bundle is indexed, so the two
+	// reductions are chained rather than tried independently: an
+	// absolute *and* versioned reference
+	// ("http://example.org/fhir/Patient/123/_history/2") needs both
+	// applied, in that order, before it matches "Patient/123".
+	unversioned := reference
+	if key, ok := stripHistory(reference); ok {
+		unversioned = key
+		if raw, ok := c.bundle[unversioned]; ok {
+			return raw, true
+		}
+	}
+
+	if key, ok := lastTwoSegments(unversioned); ok {
+		if raw, ok := c.bundle[key]; ok {
+			return raw, true
+		}
+	}
+
+	return nil, false
+}
+
+func indexContained(resourceJSON []byte) containedIndex {
+	var root struct {
+		Contained []json.RawMessage `json:"contained"`
+	}
+	if err := json.Unmarshal(resourceJSON, &root); err != nil {
+		return nil
+	}
+
+	idx := make(containedIndex, len(root.Contained))
+	for _, raw := range root.Contained {
+		var entry struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(raw, &entry); err != nil || entry.ID == "" {
+			continue
+		}
+		idx[entry.ID] = raw
+	}
+	if len(idx) == 0 {
+		return nil
+	}
+	return idx
+}
+
+func indexBundle(bundleJSON []byte) bundleIndex {
+	var bundle struct {
+		Entry []struct {
+			FullURL  string          `json:"fullUrl"`
+			Resource json.RawMessage `json:"resource"`
+		} `json:"entry"`
+	}
+	if err := json.Unmarshal(bundleJSON, &bundle); err != nil {
+		return nil
+	}
+
+	idx := make(bundleIndex, len(bundle.Entry)*2)
+	for _, entry := range bundle.Entry {
+		if len(entry.Resource) == 0 {
+			continue
+		}
+		if entry.FullURL != "" {
+			idx[entry.FullURL] = entry.Resource
+		}
+
+		var resource struct {
+			ResourceType string `json:"resourceType"`
+			ID           string `json:"id"`
+		}
+		if err := json.Unmarshal(entry.Resource, &resource); err == nil &&
+			resource.ResourceType != "" && resource.ID != "" {
+			idx[resource.ResourceType+"/"+resource.ID] = entry.Resource
+		}
+	}
+	if len(idx) == 0 {
+		return nil
+	}
+	return idx
+}
+
+// stripHistory strips a trailing "/_history/<version>" segment from a
+// reference, returning the unversioned reference.
+func stripHistory(reference string) (string, bool) {
+	idx := strings.Index(reference, "/_history/")
+	if idx < 0 {
+		return "", false
+	}
+	return reference[:idx], true
+}
+
+// lastTwoSegments returns the trailing "ResourceType/id" portion of an
+// absolute reference URL, e.g.
+// "http://example.org/fhir/Patient/123" -> "Patient/123".
+func lastTwoSegments(reference string) (string, bool) {
+	parts := strings.Split(reference, "/")
+	if len(parts) < 2 {
+		return "", false
+	}
+	return strings.Join(parts[len(parts)-2:], "/"), true
+}
@@ -0,0 +1,41 @@
+package eval
+
+import "testing"
+
+func TestResolveLocalBundle(t *testing.T) {
+	bundle := []byte(`{
+		"resourceType": "Bundle",
+		"entry": [
+			{
+				"fullUrl": "urn:uuid:9f1f0b3a-0000-0000-0000-000000000001",
+				"resource": {"resourceType": "Patient", "id": "123"}
+			}
+		]
+	}`)
+
+	tests := []struct {
+		name      string
+		reference string
+		wantMatch bool
+	}{
+		{"relative", "Patient/123", true},
+		{"urn:uuid fullUrl", "urn:uuid:9f1f0b3a-0000-0000-0000-000000000001", true},
+		{"versioned relative", "Patient/123/_history/2", true},
+		{"absolute", "http://example.org/fhir/Patient/123", true},
+		{"absolute and versioned", "http://example.org/fhir/Patient/123/_history/2", true},
+		{"unrelated", "Patient/999", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := NewContext(nil).WithBundle(bundle)
+			raw, ok := ctx.ResolveLocal(tt.reference)
+			if ok != tt.wantMatch {
+				t.Fatalf("ResolveLocal(%q) ok = %v, want %v", tt.reference, ok, tt.wantMatch)
+			}
+			if ok && len(raw) == 0 {
+				t.Fatalf("ResolveLocal(%q) returned a match with no data", tt.reference)
+			}
+		})
+	}
+}
@@ -0,0 +1,203 @@
+// Package eval holds the evaluation context and evaluator that walk a
+// parsed FHIRPath expression tree against FHIR data.
+package eval
+
+import (
+	"context"
+
+	"github.com/gofhir/fhirpath/types"
+)
+
+// Context carries everything an evaluation needs beyond the expression
+// tree itself: the root resource being evaluated, and the optional
+// collaborators (Resolver, TerminologyService, ProfileValidator) that
+// FHIR-aware functions fall back on.
+//
+// Context is copy-on-write: every With* method returns a new *Context
+// with one field changed, leaving the receiver untouched. This lets a
+// caller fan an expression out over several sibling scopes (e.g. a
+// forEach) without them leaking state into one another.
+type Context struct {
+	ctx      context.Context
+	resource []byte
+
+	resolver           Resolver
+	terminologyService TerminologyService
+	profileValidator   ProfileValidator
+
+	bundle    bundleIndex
+	contained containedIndex
+
+	resolverConcurrency int
+	resolveCache        *resolveCache
+
+	variables map[string]types.Collection
+
+	observer Observer
+}
+
+// defaultResolverConcurrency bounds the worker pool fnResolve falls
+// back to when the configured Resolver doesn't implement BatchResolver.
+const defaultResolverConcurrency = 8
+
+// defaultResolveCacheSize is the capacity of the per-Context resolved-
+// reference LRU.
+const defaultResolveCacheSize = 256
+
+// NewContext creates a Context for evaluating expressions against the
+// given JSON resource. %resource and %rootResource are populated from
+// resource, and %ucum/%sct/%loinc are populated with their well-known
+// canonical URLs.
+func NewContext(resource []byte) *Context {
+	c := &Context{
+		ctx:          context.Background(),
+		resource:     resource,
+		contained:    indexContained(resource),
+		resolveCache: newResolveCache(defaultResolveCacheSize),
+	}
+
+	if col, err := types.JSONToCollection(resource); err == nil {
+		c = c.setVariable("resource", col)
+		c = c.setVariable("rootResource", col)
+	}
+	for name, url := range wellKnownVariables {
+		c = c.setVariable(name, types.Collection{types.NewString(url)})
+	}
+
+	return c
+}
+
+// clone returns a shallow copy of c, the basis for every With* method.
+func (c *Context) clone() *Context {
+	cp := *c
+	return &cp
+}
+
+// Context returns the Go context.Context to use for any I/O a
+// collaborator (Resolver, TerminologyService, ...) performs.
+func (c *Context) Context() context.Context {
+	return c.ctx
+}
+
+// WithGoContext returns a copy of c that carries ctx for collaborator
+// I/O instead of context.Background().
+func (c *Context) WithGoContext(ctx context.Context) *Context {
+	cp := c.clone()
+	cp.ctx = ctx
+	return cp
+}
+
+// Resource returns the root resource this Context was created for.
+func (c *Context) Resource() []byte {
+	return c.resource
+}
+
+// WithResource returns a copy of c re-rooted onto resource: %resource
+// is rebound to resource, so evaluating an expression against the
+// returned Context starts from it rather than from c's original
+// resource (%rootResource keeps c's original). This is how a caller iterating a
+// collection by hand (ViewDefinition's forEach, for instance) evaluates
+// the rest of a pipeline with each item as the current focus, without
+// losing the collaborators and variables already bound on c.
+func (c *Context) WithResource(resource []byte) *Context {
+	cp := c.clone()
+	cp.resource = resource
+	cp.contained = indexContained(resource)
+	if col, err := types.JSONToCollection(resource); err == nil {
+		cp = cp.setVariable("resource", col)
+	}
+	return cp
+}
+
+// WithResolver returns a copy of c that uses r to resolve references
+// fnResolve can't satisfy locally.
+func (c *Context) WithResolver(r Resolver) *Context {
+	cp := c.clone()
+	cp.resolver = r
+	return cp
+}
+
+// GetResolver returns the Resolver in scope, or nil if none was set.
+func (c *Context) GetResolver() Resolver {
+	return c.resolver
+}
+
+// WithResolverConcurrency returns a copy of c that runs at most n
+// concurrent Resolve calls when falling back to a plain (non-batch)
+// Resolver. n <= 0 restores the default.
+func (c *Context) WithResolverConcurrency(n int) *Context {
+	cp := c.clone()
+	cp.resolverConcurrency = n
+	return cp
+}
+
+// ResolverConcurrency returns the configured worker-pool size for the
+// plain-Resolver fallback, defaulting to defaultResolverConcurrency.
+func (c *Context) ResolverConcurrency() int {
+	if c.resolverConcurrency > 0 {
+		return c.resolverConcurrency
+	}
+	return defaultResolverConcurrency
+}
+
+// CachedResolution returns the previously fetched JSON for reference,
+// if fnResolve has already fetched it earlier in this evaluation.
+func (c *Context) CachedResolution(reference string) ([]byte, bool) {
+	if c.resolveCache == nil {
+		return nil, false
+	}
+	return c.resolveCache.get(reference)
+}
+
+// CacheResolution records the fetched JSON for reference so a repeated
+// resolve() of the same reference later in the same expression is
+// served from memory instead of refetched.
+func (c *Context) CacheResolution(reference string, data []byte) {
+	if c.resolveCache == nil {
+		return
+	}
+	c.resolveCache.put(reference, data)
+}
+
+// WithTerminologyService returns a copy of c that uses ts for
+// memberOf/conformsTo-style terminology validation.
+func (c *Context) WithTerminologyService(ts TerminologyService) *Context {
+	cp := c.clone()
+	cp.terminologyService = ts
+	return cp
+}
+
+// GetTerminologyService returns the TerminologyService in scope, or
+// nil if none was set.
+func (c *Context) GetTerminologyService() TerminologyService {
+	return c.terminologyService
+}
+
+// WithProfileValidator returns a copy of c that uses pv for
+// conformsTo.
+func (c *Context) WithProfileValidator(pv ProfileValidator) *Context {
+	cp := c.clone()
+	cp.profileValidator = pv
+	return cp
+}
+
+// GetProfileValidator returns the ProfileValidator in scope, or nil if
+// none was set.
+func (c *Context) GetProfileValidator() ProfileValidator {
+	return c.profileValidator
+}
+
+// WithObserver returns a copy of c that reports trace() calls and
+// evaluation steps to obs. Leaving this unset (the default) keeps
+// evaluation on its zero-alloc fast path: every hook site is a single
+// "is observer nil" check away from a no-op.
+func (c *Context) WithObserver(obs Observer) *Context {
+	cp := c.clone()
+	cp.observer = obs
+	return cp
+}
+
+// GetObserver returns the Observer in scope, or nil if none was set.
+func (c *Context) GetObserver() Observer {
+	return c.observer
+}
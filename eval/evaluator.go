@@ -0,0 +1,287 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/gofhir/fhirpath/parser"
+	"github.com/gofhir/fhirpath/types"
+)
+
+// Evaluator walks a parsed FHIRPath expression tree against a Context,
+// dispatching function-call nodes through a Registry.
+type Evaluator struct {
+	ctx      *Context
+	registry Registry
+}
+
+// NewEvaluator returns an Evaluator that runs expressions against ctx,
+// looking up functions in registry.
+func NewEvaluator(ctx *Context, registry Registry) *Evaluator {
+	return &Evaluator{ctx: ctx, registry: registry}
+}
+
+// Evaluate runs tree against the Evaluator's Context, starting with
+// %resource (which WithResource rebinds when it re-roots the Context)
+// as the input collection.
+func (e *Evaluator) Evaluate(tree parser.Node) (types.Collection, error) {
+	input, _ := e.ctx.GetVariable("resource")
+	result, _, err := e.evalNode(tree, e.ctx, input)
+	return result, err
+}
+
+// evalNode evaluates node against ctx and input, returning its result
+// and the Context that evaluation of whatever follows should continue
+// with — ordinarily ctx itself, but a FuncDef with a Bind (like
+// defineVariable()) can hand back a Context with a new %variable
+// bound for the rest of the pipeline.
+func (e *Evaluator) evalNode(node parser.Node, ctx *Context, input types.Collection) (types.Collection, *Context, error) {
+	result, nextCtx, err := e.dispatch(node, ctx, input)
+	if err == nil {
+		if obs := ctx.GetObserver(); obs != nil {
+			obs.OnNodeEval(node.String(), result)
+		}
+	}
+	return result, nextCtx, err
+}
+
+func (e *Evaluator) dispatch(node parser.Node, ctx *Context, input types.Collection) (types.Collection, *Context, error) {
+	switch n := node.(type) {
+	case parser.StringLiteral:
+		return types.Collection{types.NewString(n.Value)}, ctx, nil
+
+	case parser.NumberLiteral:
+		return types.Collection{types.NewDecimal(n.Value)}, ctx, nil
+
+	case parser.BoolLiteral:
+		return types.Collection{types.NewBoolean(n.Value)}, ctx, nil
+
+	case parser.This:
+		return input, ctx, nil
+
+	case parser.Variable:
+		value, ok := ctx.GetVariable(n.Name)
+		if !ok {
+			return types.Collection{}, ctx, nil
+		}
+		return value, ctx, nil
+
+	case parser.Identifier:
+		return e.navigate(input, n.Name), ctx, nil
+
+	case parser.Call:
+		return e.evalCall(n, ctx, input)
+
+	case parser.Path:
+		curCtx := ctx
+		curInput := input
+		var result types.Collection
+		for i, step := range n.Steps {
+			if i == 0 {
+				if id, ok := step.(parser.Identifier); ok && rootMatchesType(curInput, id.Name) {
+					result = curInput
+					continue
+				}
+			}
+			var err error
+			result, curCtx, err = e.evalNode(step, curCtx, curInput)
+			if err != nil {
+				return nil, curCtx, err
+			}
+			curInput = result
+		}
+		return result, curCtx, nil
+
+	case parser.Binary:
+		return e.evalBinary(n, ctx, input)
+
+	default:
+		return nil, ctx, fmt.Errorf("eval: unsupported expression node %T", node)
+	}
+}
+
+// rootMatchesType reports whether input is a single resource whose
+// resourceType equals name — FHIRPath expressions are conventionally
+// rooted at a type name (`Observation.code...`) even though evaluation
+// actually starts at the resource itself, so that leading step is a
+// no-op rather than a navigation into a field called "Observation".
+func rootMatchesType(input types.Collection, name string) bool {
+	if len(input) != 1 {
+		return false
+	}
+	obj, ok := input[0].(*types.ObjectValue)
+	if !ok {
+		return false
+	}
+	rt, ok := obj.Get("resourceType")
+	if !ok {
+		return false
+	}
+	s, ok := rt.(types.String)
+	return ok && s.Value() == name
+}
+
+// navigate returns the named field of every ObjectValue in input,
+// flattened into one Collection; non-object items contribute nothing.
+func (e *Evaluator) navigate(input types.Collection, name string) types.Collection {
+	var result types.Collection
+	for _, item := range input {
+		obj, ok := item.(*types.ObjectValue)
+		if !ok {
+			continue
+		}
+		result = append(result, obj.GetCollection(name)...)
+	}
+	return result
+}
+
+func (e *Evaluator) evalCall(n parser.Call, ctx *Context, input types.Collection) (types.Collection, *Context, error) {
+	def, ok := e.registry.Lookup(n.Name)
+	if !ok {
+		return nil, ctx, fmt.Errorf("eval: undefined function %q", n.Name)
+	}
+
+	if len(n.Args) < def.MinArgs || (def.MaxArgs >= 0 && len(n.Args) > def.MaxArgs) {
+		return nil, ctx, fmt.Errorf("eval: %s() takes %d-%d arguments, got %d", n.Name, def.MinArgs, def.MaxArgs, len(n.Args))
+	}
+
+	if def.LazyFn != nil {
+		return e.evalLazyCall(n, def, ctx, input)
+	}
+
+	args := make([]interface{}, len(n.Args))
+	argCols := make([]types.Collection, len(n.Args))
+	for i, argNode := range n.Args {
+		col, _, err := e.evalNode(argNode, ctx, input)
+		if err != nil {
+			return nil, ctx, err
+		}
+		args[i] = col
+		argCols[i] = col
+	}
+
+	if obs := ctx.GetObserver(); obs != nil {
+		obs.OnFunctionEnter(n.Name, input, argCols)
+	}
+
+	result, err := def.Fn(ctx, input, args)
+
+	if obs := ctx.GetObserver(); obs != nil {
+		obs.OnFunctionExit(n.Name, result, err)
+	}
+	if err != nil {
+		return nil, ctx, err
+	}
+
+	nextCtx := ctx
+	if def.Bind != nil {
+		nextCtx, err = def.Bind(ctx, input, args)
+		if err != nil {
+			return nil, ctx, err
+		}
+	}
+
+	return result, nextCtx, nil
+}
+
+// evalLazyCall dispatches to a LazyFn, which controls itself when and
+// how many times each argument expression is evaluated — unlike Fn,
+// args are never evaluated up front. evalArg re-evaluates argNode with
+// $this (and plain identifier navigation) rebound to a single item.
+func (e *Evaluator) evalLazyCall(n parser.Call, def FuncDef, ctx *Context, input types.Collection) (types.Collection, *Context, error) {
+	evalArg := func(node parser.Node, item types.Value) (types.Collection, error) {
+		result, _, err := e.evalNode(node, ctx, types.Collection{item})
+		return result, err
+	}
+
+	if obs := ctx.GetObserver(); obs != nil {
+		obs.OnFunctionEnter(n.Name, input, nil)
+	}
+
+	result, err := def.LazyFn(ctx, input, n.Args, evalArg)
+
+	if obs := ctx.GetObserver(); obs != nil {
+		obs.OnFunctionExit(n.Name, result, err)
+	}
+	if err != nil {
+		return nil, ctx, err
+	}
+
+	return result, ctx, nil
+}
+
+func (e *Evaluator) evalBinary(n parser.Binary, ctx *Context, input types.Collection) (types.Collection, *Context, error) {
+	left, ctx, err := e.evalNode(n.Left, ctx, input)
+	if err != nil {
+		return nil, ctx, err
+	}
+
+	switch n.Op {
+	case "and", "or":
+		right, ctx, err := e.evalNode(n.Right, ctx, input)
+		if err != nil {
+			return nil, ctx, err
+		}
+		lb, lok := singleBool(left)
+		rb, rok := singleBool(right)
+		if !lok || !rok {
+			return types.Collection{}, ctx, nil
+		}
+		if n.Op == "and" {
+			return types.Collection{types.NewBoolean(lb && rb)}, ctx, nil
+		}
+		return types.Collection{types.NewBoolean(lb || rb)}, ctx, nil
+
+	case "=", "!=":
+		right, ctx, err := e.evalNode(n.Right, ctx, input)
+		if err != nil {
+			return nil, ctx, err
+		}
+		eq := collectionsEqual(left, right)
+		if n.Op == "!=" {
+			eq = !eq
+		}
+		return types.Collection{types.NewBoolean(eq)}, ctx, nil
+
+	default:
+		return nil, ctx, fmt.Errorf("eval: unsupported operator %q", n.Op)
+	}
+}
+
+func singleBool(col types.Collection) (bool, bool) {
+	if len(col) != 1 {
+		return false, false
+	}
+	b, ok := col[0].(types.Boolean)
+	if !ok {
+		return false, false
+	}
+	return b.Value(), true
+}
+
+func collectionsEqual(a, b types.Collection) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !valuesEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func valuesEqual(a, b types.Value) bool {
+	switch av := a.(type) {
+	case types.String:
+		bv, ok := b.(types.String)
+		return ok && av.Value() == bv.Value()
+	case types.Boolean:
+		bv, ok := b.(types.Boolean)
+		return ok && av.Value() == bv.Value()
+	case types.Decimal:
+		bv, ok := b.(types.Decimal)
+		return ok && av.Value().Equal(bv.Value())
+	default:
+		return false
+	}
+}
@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/gofhir/fhirpath/parser"
+	"github.com/gofhir/fhirpath/types"
+)
+
+type stubRegistry map[string]FuncDef
+
+func (r stubRegistry) Lookup(name string) (FuncDef, bool) {
+	def, ok := r[name]
+	return def, ok
+}
+
+// TestEvaluatorFiresObserverHooks proves OnFunctionEnter/OnFunctionExit
+// and OnNodeEval actually fire from the evaluator loop, not just from
+// trace()'s direct OnTrace call.
+func TestEvaluatorFiresObserverHooks(t *testing.T) {
+	registry := stubRegistry{
+		"upper": FuncDef{
+			Name:    "upper",
+			MinArgs: 0,
+			MaxArgs: 0,
+			Fn: func(ctx *Context, input types.Collection, args []interface{}) (types.Collection, error) {
+				return input, nil
+			},
+		},
+	}
+
+	obs := NewCollectingObserver()
+	ctx := NewContext([]byte(`{"resourceType":"Patient"}`)).WithObserver(obs)
+
+	tree, err := parser.Parse("upper()")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	if _, err := NewEvaluator(ctx, registry).Evaluate(tree); err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+
+	if len(obs.FunctionEnters) != 1 || obs.FunctionEnters[0].Name != "upper" {
+		t.Fatalf("expected one OnFunctionEnter(\"upper\", ...) call, got %#v", obs.FunctionEnters)
+	}
+	if len(obs.FunctionExits) != 1 || obs.FunctionExits[0].Name != "upper" {
+		t.Fatalf("expected one OnFunctionExit(\"upper\", ...) call, got %#v", obs.FunctionExits)
+	}
+	if len(obs.Nodes) == 0 {
+		t.Fatal("expected at least one OnNodeEval call from evaluating the expression tree")
+	}
+}
@@ -0,0 +1,23 @@
+package eval
+
+import "github.com/gofhir/fhirpath/types"
+
+// Observer receives callbacks as an expression evaluates, for building
+// step-by-step debuggers or logging the intermediate result of every
+// function call in a chained expression. It is entirely optional: a
+// Context with no Observer set (the default) costs nothing extra to
+// evaluate.
+type Observer interface {
+	// OnTrace is called for every trace(name [, selector]) invocation,
+	// with the (possibly selector-narrowed) values being traced.
+	OnTrace(name string, values types.Collection)
+
+	// OnFunctionEnter/OnFunctionExit bracket every FHIRPath function
+	// call the evaluator makes.
+	OnFunctionEnter(name string, input types.Collection, args []types.Collection)
+	OnFunctionExit(name string, result types.Collection, err error)
+
+	// OnNodeEval is called after every expression tree node that
+	// evaluates without error, tagged with the node's source text.
+	OnNodeEval(source string, result types.Collection)
+}
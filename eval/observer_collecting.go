@@ -0,0 +1,76 @@
+package eval
+
+import (
+	"sync"
+
+	"github.com/gofhir/fhirpath/types"
+)
+
+// TraceEvent records one trace() call observed by a CollectingObserver.
+type TraceEvent struct {
+	Name   string
+	Values types.Collection
+}
+
+// FunctionEnter records one OnFunctionEnter call observed by a
+// CollectingObserver.
+type FunctionEnter struct {
+	Name  string
+	Input types.Collection
+	Args  []types.Collection
+}
+
+// FunctionExit records one OnFunctionExit call observed by a
+// CollectingObserver.
+type FunctionExit struct {
+	Name   string
+	Result types.Collection
+	Err    error
+}
+
+// NodeEval records one OnNodeEval call observed by a
+// CollectingObserver.
+type NodeEval struct {
+	Source string
+	Result types.Collection
+}
+
+// CollectingObserver is an Observer that records every callback it
+// receives, in order, for tests to assert against.
+type CollectingObserver struct {
+	mu sync.Mutex
+
+	Traces         []TraceEvent
+	FunctionEnters []FunctionEnter
+	FunctionExits  []FunctionExit
+	Nodes          []NodeEval
+}
+
+// NewCollectingObserver returns an empty CollectingObserver.
+func NewCollectingObserver() *CollectingObserver {
+	return &CollectingObserver{}
+}
+
+func (o *CollectingObserver) OnTrace(name string, values types.Collection) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Traces = append(o.Traces, TraceEvent{Name: name, Values: values})
+}
+
+func (o *CollectingObserver) OnFunctionEnter(name string, input types.Collection, args []types.Collection) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.FunctionEnters = append(o.FunctionEnters, FunctionEnter{Name: name, Input: input, Args: args})
+}
+
+func (o *CollectingObserver) OnFunctionExit(name string, result types.Collection, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.FunctionExits = append(o.FunctionExits, FunctionExit{Name: name, Result: result, Err: err})
+}
+
+func (o *CollectingObserver) OnNodeEval(source string, result types.Collection) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.Nodes = append(o.Nodes, NodeEval{Source: source, Result: result})
+}
@@ -0,0 +1,68 @@
+package eval
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/gofhir/fhirpath/types"
+)
+
+// TeeObserver is an Observer that writes one JSON line per trace()
+// call to an io.Writer (a log file, stdout, ...). It only reacts to
+// OnTrace; compose it with another Observer if you also need the
+// function/node-level hooks.
+type TeeObserver struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewTeeObserver returns a TeeObserver writing NDJSON to w.
+func NewTeeObserver(w io.Writer) *TeeObserver {
+	return &TeeObserver{enc: json.NewEncoder(w)}
+}
+
+type traceLine struct {
+	Trace  string        `json:"trace"`
+	Values []interface{} `json:"values"`
+}
+
+func (t *TeeObserver) OnTrace(name string, values types.Collection) {
+	cells := make([]interface{}, len(values))
+	for i, v := range values {
+		cells[i] = traceCellValue(v)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Encoding errors have nowhere useful to go from inside an
+	// Observer callback; a broken log sink shouldn't fail evaluation.
+	_ = t.enc.Encode(traceLine{Trace: name, Values: cells})
+}
+
+func (t *TeeObserver) OnFunctionEnter(name string, input types.Collection, args []types.Collection) {}
+func (t *TeeObserver) OnFunctionExit(name string, result types.Collection, err error)               {}
+func (t *TeeObserver) OnNodeEval(source string, result types.Collection)                            {}
+
+// traceCellValue renders a traced Value as something encoding/json
+// can serialize directly.
+func traceCellValue(v types.Value) interface{} {
+	switch vv := v.(type) {
+	case types.String:
+		return vv.Value()
+	case types.Boolean:
+		return vv.Value()
+	case types.Decimal:
+		return vv.Value()
+	case *types.ObjectValue:
+		return json.RawMessage(vv.Data())
+	case types.Collection:
+		cells := make([]interface{}, len(vv))
+		for i, item := range vv {
+			cells[i] = traceCellValue(item)
+		}
+		return cells
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,8 @@
+package eval
+
+import "context"
+
+// ProfileValidator backs the conformsTo() FHIRPath function.
+type ProfileValidator interface {
+	ConformsTo(ctx context.Context, resourceJSON []byte, profileURL string) (bool, error)
+}
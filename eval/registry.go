@@ -0,0 +1,48 @@
+package eval
+
+import (
+	"github.com/gofhir/fhirpath/parser"
+	"github.com/gofhir/fhirpath/types"
+)
+
+// FuncDef is a registered FHIRPath function implementation, as built
+// by the funcs package and looked up by the Evaluator for every
+// function-call node it encounters. Exactly one of Fn or LazyFn should
+// be set.
+type FuncDef struct {
+	Name    string
+	MinArgs int
+	MaxArgs int
+
+	// Fn implements an ordinary function: it receives the (already
+	// evaluated) input collection and argument collections, and
+	// returns the function's result. Most functions are this shape.
+	Fn func(ctx *Context, input types.Collection, args []interface{}) (types.Collection, error)
+
+	// LazyFn implements a function whose arguments are expressions to
+	// be evaluated once per input item, with $this (and plain
+	// identifier navigation) rebound to that item — where(), exists(),
+	// and the same family select()/all()/repeat() belong to. The
+	// Evaluator passes argNodes unevaluated, plus evalArg to run one of
+	// them against a single item, so LazyFn controls exactly when and
+	// how many times each argument runs instead of the Evaluator
+	// eagerly evaluating it once up front.
+	LazyFn func(ctx *Context, input types.Collection, argNodes []parser.Node, evalArg func(node parser.Node, item types.Value) (types.Collection, error)) (types.Collection, error)
+
+	// Bind optionally lets a function rebind variables for the rest of
+	// the pipeline it's called in, the way defineVariable() does. When
+	// set, the Evaluator calls it right after a successful Fn call and
+	// threads the *Context it returns into evaluation of whatever
+	// follows — the normal Context.With* methods can't do this on
+	// their own because a FuncDef only ever sees the Context it's
+	// called with, not a way to hand a new one back to the caller.
+	// Bind is not supported alongside LazyFn; none of the
+	// per-item-evaluation functions need to rebind variables.
+	Bind func(ctx *Context, input types.Collection, args []interface{}) (*Context, error)
+}
+
+// Registry looks up a FuncDef by name for the Evaluator to dispatch
+// function-call nodes to.
+type Registry interface {
+	Lookup(name string) (FuncDef, bool)
+}
@@ -0,0 +1,55 @@
+package eval
+
+import "container/list"
+
+// resolveCache is a fixed-capacity LRU of reference -> resolved JSON,
+// scoped to a single Context (and everything cloned from it via
+// With*), so a reference dereferenced multiple times in one expression
+// is only ever fetched once.
+type resolveCache struct {
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // front = most recently used
+}
+
+type resolveCacheEntry struct {
+	key   string
+	value []byte
+}
+
+func newResolveCache(capacity int) *resolveCache {
+	return &resolveCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+func (c *resolveCache) get(key string) ([]byte, bool) {
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*resolveCacheEntry).value, true
+}
+
+func (c *resolveCache) put(key string, value []byte) {
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*resolveCacheEntry).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&resolveCacheEntry{key: key, value: value})
+	c.entries[key] = el
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resolveCacheEntry).key)
+	}
+}
@@ -0,0 +1,28 @@
+package eval
+
+import "context"
+
+// Resolver fetches the JSON for a FHIR reference (e.g. "Patient/123",
+// an absolute URL, or a "urn:uuid:..." fullUrl) from wherever the host
+// application keeps its resources. It is the fallback fnResolve uses
+// once local resolution against a Bundle or contained resources has
+// been tried and failed.
+type Resolver interface {
+	Resolve(ctx context.Context, reference string) ([]byte, error)
+}
+
+// BatchResolver is an optional extension of Resolver that a host
+// application implements when it can fetch several references in one
+// round trip (a single `_id=a,b,c` search, a DataLoader, ...). fnResolve
+// type-asserts for this before falling back to a bounded worker pool
+// over the plain Resolver, so expressions like
+// `Bundle.entry.resource.subject.resolve()` don't pay for an N+1 fetch
+// per reference.
+//
+// refs and the two returned slices are positionally aligned: results[i]
+// (or errs[i]) corresponds to refs[i]. A reference that can't be
+// resolved should report a non-nil errs[i]; fnResolve treats that and
+// a nil results[i] alike, resolving the reference to empty.
+type BatchResolver interface {
+	ResolveBatch(ctx context.Context, refs []string) (results [][]byte, errs []error)
+}
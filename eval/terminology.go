@@ -0,0 +1,52 @@
+package eval
+
+import "context"
+
+// Coding is the minimal system/code/version/display tuple the
+// terminology operations exchange, independent of how the caller's
+// FHIR library represents a Coding.
+type Coding struct {
+	System  string
+	Code    string
+	Version string
+	Display string
+}
+
+// Relationship is the result of Subsumes, mirroring the codes of the
+// FHIR $subsumes operation.
+type Relationship string
+
+const (
+	RelationshipEquivalent  Relationship = "equivalent"
+	RelationshipSubsumes    Relationship = "subsumes"
+	RelationshipSubsumedBy  Relationship = "subsumed-by"
+	RelationshipNotSubsumed Relationship = "not-subsumed"
+)
+
+// TerminologyService backs the memberOf() FHIRPath function. code is
+// the map[string]interface{} representation produced by
+// funcs.extractCodeValue (a code, Coding, or CodeableConcept).
+type TerminologyService interface {
+	MemberOf(ctx context.Context, code interface{}, valueSetURL string) (bool, error)
+}
+
+// TerminologyExpander is an optional extension of TerminologyService
+// that backs %terminologies.expand(), translate(), and subsumes(),
+// mirroring how BatchResolver optionally extends Resolver: a host's
+// TerminologyService that only implements MemberOf keeps compiling
+// unchanged, and the expand/translate/subsumes functions type-assert
+// for this before calling any of its methods.
+type TerminologyExpander interface {
+	// Expand returns the Codings in the ValueSet identified by
+	// valueSetURL, optionally narrowed by filter (a $expand-style text
+	// filter; empty means no filtering).
+	Expand(ctx context.Context, valueSetURL, filter string) ([]Coding, error)
+
+	// Translate maps source through the ConceptMap identified by
+	// conceptMapURL, returning its equivalent Coding(s) in the target
+	// system(s).
+	Translate(ctx context.Context, source Coding, conceptMapURL string) ([]Coding, error)
+
+	// Subsumes reports how codeA and codeB relate within system.
+	Subsumes(ctx context.Context, system, codeA, codeB string) (Relationship, error)
+}
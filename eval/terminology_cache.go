@@ -0,0 +1,174 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TerminologyCacheOptions configures WithTerminologyCache.
+type TerminologyCacheOptions struct {
+	// TTL is how long a successful result is cached. Zero disables
+	// caching of successful results.
+	TTL time.Duration
+
+	// NegativeTTL is how long an error result (e.g. ValueSet not
+	// found) is cached, so a missing ValueSet isn't re-fetched on
+	// every evaluation. Zero disables negative caching.
+	NegativeTTL time.Duration
+}
+
+// WithTerminologyCache returns a copy of c whose TerminologyService is
+// wrapped with an in-process cache keyed by (operation, url, version,
+// params), honoring opts' TTL and negative-caching. It's a no-op if c
+// has no TerminologyService set. If the TerminologyService also
+// implements TerminologyExpander, the returned wrapper does too, so
+// expand()/translate()/subsumes() get the same caching as memberOf().
+func (c *Context) WithTerminologyCache(opts TerminologyCacheOptions) *Context {
+	if c.terminologyService == nil {
+		return c
+	}
+	cp := c.clone()
+	base := &cachingTerminologyService{
+		inner:   c.terminologyService,
+		opts:    opts,
+		entries: make(map[string]*terminologyCacheEntry),
+	}
+	if expander, ok := c.terminologyService.(TerminologyExpander); ok {
+		cp.terminologyService = &cachingTerminologyExpander{
+			cachingTerminologyService: base,
+			expander:                  expander,
+		}
+	} else {
+		cp.terminologyService = base
+	}
+	return cp
+}
+
+type terminologyCacheEntry struct {
+	memberOf  bool
+	expand    []Coding
+	translate []Coding
+	subsumes  Relationship
+	err       error
+	expiresAt time.Time
+}
+
+// cachingTerminologyService wraps a TerminologyService with a TTL +
+// negative-caching layer, so repeated lookups of the same
+// (operation, url, version, params) within the cache's lifetime don't
+// re-hit the wrapped service.
+type cachingTerminologyService struct {
+	inner TerminologyService
+	opts  TerminologyCacheOptions
+
+	mu      sync.Mutex
+	entries map[string]*terminologyCacheEntry
+}
+
+// cachingTerminologyExpander adds cached Expand/Translate/Subsumes on
+// top of a cachingTerminologyService, sharing its entry store and TTL
+// rules. It only exists when the wrapped TerminologyService also
+// implements TerminologyExpander.
+type cachingTerminologyExpander struct {
+	*cachingTerminologyService
+	expander TerminologyExpander
+}
+
+func (c *cachingTerminologyService) ttlFor(err error) time.Duration {
+	if err != nil {
+		return c.opts.NegativeTTL
+	}
+	return c.opts.TTL
+}
+
+func (c *cachingTerminologyService) lookup(key string) (*terminologyCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *cachingTerminologyService) store(key string, entry *terminologyCacheEntry) {
+	ttl := c.ttlFor(entry.err)
+	if ttl <= 0 {
+		return
+	}
+	entry.expiresAt = time.Now().Add(ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+func (c *cachingTerminologyService) MemberOf(ctx context.Context, code interface{}, valueSetURL string) (bool, error) {
+	key := cacheKey("memberOf", valueSetURL, "", fmt.Sprint(code))
+	if entry, ok := c.lookup(key); ok {
+		return entry.memberOf, entry.err
+	}
+
+	value, err := c.inner.MemberOf(ctx, code, valueSetURL)
+	c.store(key, &terminologyCacheEntry{memberOf: value, err: err})
+	return value, err
+}
+
+func (c *cachingTerminologyExpander) Expand(ctx context.Context, valueSetURL, filter string) ([]Coding, error) {
+	key := cacheKey("expand", valueSetURL, "", filter)
+	if entry, ok := c.lookup(key); ok {
+		return entry.expand, entry.err
+	}
+
+	value, err := c.expander.Expand(ctx, valueSetURL, filter)
+	c.store(key, &terminologyCacheEntry{expand: value, err: err})
+	return value, err
+}
+
+func (c *cachingTerminologyExpander) Translate(ctx context.Context, source Coding, conceptMapURL string) ([]Coding, error) {
+	key := cacheKey("translate", conceptMapURL, source.Version, source.System, source.Code)
+	if entry, ok := c.lookup(key); ok {
+		return entry.translate, entry.err
+	}
+
+	value, err := c.expander.Translate(ctx, source, conceptMapURL)
+	c.store(key, &terminologyCacheEntry{translate: value, err: err})
+	return value, err
+}
+
+func (c *cachingTerminologyExpander) Subsumes(ctx context.Context, system, codeA, codeB string) (Relationship, error) {
+	key := cacheKey("subsumes", system, "", codeA, codeB)
+	if entry, ok := c.lookup(key); ok {
+		return entry.subsumes, entry.err
+	}
+
+	value, err := c.expander.Subsumes(ctx, system, codeA, codeB)
+	c.store(key, &terminologyCacheEntry{subsumes: value, err: err})
+	return value, err
+}
+
+// cacheKey joins its fields into a single lookup key, escaping each
+// field so that a "|" occurring inside a field (system|code tokens are
+// conventionally written that way) can never be mistaken for the
+// separator between fields.
+func cacheKey(fields ...string) string {
+	escaped := make([]string, len(fields))
+	for i, f := range fields {
+		escaped[i] = escapeCacheKeyField(f)
+	}
+	return strings.Join(escaped, "|")
+}
+
+func escapeCacheKeyField(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return s
+}
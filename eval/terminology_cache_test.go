@@ -0,0 +1,114 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memberOfOnlyService implements TerminologyService but deliberately
+// not TerminologyExpander, the way a host that only supports
+// memberOf() would.
+type memberOfOnlyService struct {
+	calls int
+}
+
+func (s *memberOfOnlyService) MemberOf(ctx context.Context, code interface{}, valueSetURL string) (bool, error) {
+	s.calls++
+	return true, nil
+}
+
+// expandingService implements both TerminologyService and
+// TerminologyExpander, and counts calls so tests can assert caching
+// actually avoided a re-hit.
+type expandingService struct {
+	expandCalls int
+	expandErr   error
+}
+
+func (s *expandingService) MemberOf(ctx context.Context, code interface{}, valueSetURL string) (bool, error) {
+	return false, nil
+}
+
+func (s *expandingService) Expand(ctx context.Context, valueSetURL, filter string) ([]Coding, error) {
+	s.expandCalls++
+	if s.expandErr != nil {
+		return nil, s.expandErr
+	}
+	return []Coding{{System: "sys", Code: "c1"}}, nil
+}
+
+func (s *expandingService) Translate(ctx context.Context, source Coding, conceptMapURL string) ([]Coding, error) {
+	return nil, nil
+}
+
+func (s *expandingService) Subsumes(ctx context.Context, system, codeA, codeB string) (Relationship, error) {
+	return RelationshipEquivalent, nil
+}
+
+func TestWithTerminologyCachePreservesExpanderOnlyWhenInnerSupportsIt(t *testing.T) {
+	ctx := NewContext(nil).WithTerminologyService(&memberOfOnlyService{}).
+		WithTerminologyCache(TerminologyCacheOptions{TTL: time.Minute})
+
+	if _, ok := ctx.GetTerminologyService().(TerminologyExpander); ok {
+		t.Fatal("cached wrapper implements TerminologyExpander despite the inner service not supporting it")
+	}
+
+	ctx2 := NewContext(nil).WithTerminologyService(&expandingService{}).
+		WithTerminologyCache(TerminologyCacheOptions{TTL: time.Minute})
+
+	if _, ok := ctx2.GetTerminologyService().(TerminologyExpander); !ok {
+		t.Fatal("cached wrapper lost TerminologyExpander despite the inner service supporting it")
+	}
+}
+
+func TestTerminologyCacheTTL(t *testing.T) {
+	inner := &expandingService{}
+	ctx := NewContext(nil).WithTerminologyService(inner).
+		WithTerminologyCache(TerminologyCacheOptions{TTL: 20 * time.Millisecond})
+	expander := ctx.GetTerminologyService().(TerminologyExpander)
+
+	if _, err := expander.Expand(context.Background(), "vs", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expander.Expand(context.Background(), "vs", ""); err != nil {
+		t.Fatal(err)
+	}
+	if inner.expandCalls != 1 {
+		t.Fatalf("expected the second Expand to be served from cache, inner was called %d times", inner.expandCalls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, err := expander.Expand(context.Background(), "vs", ""); err != nil {
+		t.Fatal(err)
+	}
+	if inner.expandCalls != 2 {
+		t.Fatalf("expected the call after TTL expiry to re-hit the inner service, inner was called %d times", inner.expandCalls)
+	}
+}
+
+func TestTerminologyCacheNegativeTTL(t *testing.T) {
+	inner := &expandingService{expandErr: errors.New("valueset not found")}
+	ctx := NewContext(nil).WithTerminologyService(inner).
+		WithTerminologyCache(TerminologyCacheOptions{NegativeTTL: time.Minute})
+	expander := ctx.GetTerminologyService().(TerminologyExpander)
+
+	if _, err := expander.Expand(context.Background(), "vs", ""); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := expander.Expand(context.Background(), "vs", ""); err == nil {
+		t.Fatal("expected an error")
+	}
+	if inner.expandCalls != 1 {
+		t.Fatalf("expected the error to be negatively cached, inner was called %d times", inner.expandCalls)
+	}
+}
+
+func TestCacheKeyEscapesSeparator(t *testing.T) {
+	a := cacheKey("subsumes", "sys", "", "A|B")
+	b := cacheKey("subsumes", "sys|A", "", "B")
+	if a == b {
+		t.Fatalf("cacheKey collided for distinct field splits: both produced %q", a)
+	}
+}
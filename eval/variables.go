@@ -0,0 +1,66 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/gofhir/fhirpath/types"
+)
+
+// wellKnownVariables are the fixed environment variables FHIRPath
+// defines for terminology systems; NewContext seeds them so
+// expressions like `code.coding.where(system = %loinc)` work without
+// any caller setup.
+var wellKnownVariables = map[string]string{
+	"ucum":  "http://unitsofmeasure.org",
+	"sct":   "http://snomed.info/sct",
+	"loinc": "http://loinc.org",
+}
+
+// reservedVariableNames are the %-names a caller or defineVariable()
+// may never redefine: the well-known terminology URLs above, plus
+// %context (which this package never binds, so `%context` evaluates
+// to empty) and %resource/%rootResource (owned by
+// NewContext/WithResource).
+var reservedVariableNames = map[string]bool{
+	"context":      true,
+	"resource":     true,
+	"rootResource": true,
+}
+
+func init() {
+	for name := range wellKnownVariables {
+		reservedVariableNames[name] = true
+	}
+}
+
+// setVariable binds name to value without the reserved-name check
+// WithVariable applies; it's how NewContext and WithResource seed
+// %resource/%rootResource/%ucum/%sct/%loinc.
+func (c *Context) setVariable(name string, value types.Collection) *Context {
+	cp := c.clone()
+	cp.variables = make(map[string]types.Collection, len(c.variables)+1)
+	for k, v := range c.variables {
+		cp.variables[k] = v
+	}
+	cp.variables[name] = value
+	return cp
+}
+
+// WithVariable returns a copy of c with %name bound to value for the
+// rest of the pipeline evaluated against it; sibling branches built
+// from c are unaffected. name must not be one of the reserved
+// variable names (%context, %resource, %rootResource, %ucum, %sct,
+// %loinc) — this is also what backs the defineVariable() FHIRPath
+// function's validation.
+func (c *Context) WithVariable(name string, value types.Collection) (*Context, error) {
+	if reservedVariableNames[name] {
+		return nil, fmt.Errorf("eval: %%%s is a reserved variable name and cannot be redefined", name)
+	}
+	return c.setVariable(name, value), nil
+}
+
+// GetVariable returns the value bound to %name, if any.
+func (c *Context) GetVariable(name string) (types.Collection, bool) {
+	v, ok := c.variables[name]
+	return v, ok
+}
@@ -3,14 +3,24 @@ package fhirpath
 import (
 	"github.com/gofhir/fhirpath/eval"
 	"github.com/gofhir/fhirpath/funcs"
-	"github.com/gofhir/fhirpath/parser/grammar"
+	"github.com/gofhir/fhirpath/parser"
 	"github.com/gofhir/fhirpath/types"
 )
 
 // Expression represents a compiled FHIRPath expression.
 type Expression struct {
 	source string
-	tree   *grammar.EntireExpressionContext
+	tree   parser.Node
+}
+
+// Compile parses source as a FHIRPath expression, ready to be run
+// against a resource with Evaluate or EvaluateWithContext.
+func Compile(source string) (*Expression, error) {
+	tree, err := parser.Parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Expression{source: source, tree: tree}, nil
 }
 
 // Evaluate executes the expression against a JSON resource.
@@ -0,0 +1,83 @@
+package funcs
+
+import (
+	"github.com/gofhir/fhirpath/eval"
+	"github.com/gofhir/fhirpath/parser"
+	"github.com/gofhir/fhirpath/types"
+)
+
+func init() {
+	Register(FuncDef{
+		Name:    "where",
+		MinArgs: 1,
+		MaxArgs: 1,
+		LazyFn:  fnWhere,
+	})
+	Register(FuncDef{
+		Name:    "exists",
+		MinArgs: 0,
+		MaxArgs: 1,
+		LazyFn:  fnExists,
+	})
+	Register(FuncDef{
+		Name:    "first",
+		MinArgs: 0,
+		MaxArgs: 0,
+		Fn:      fnFirst,
+	})
+}
+
+// fnWhere implements input.where(criteria): criteria is evaluated once
+// per item in input, with $this rebound to that item, keeping only the
+// items for which it evaluates to true.
+func fnWhere(ctx *eval.Context, input types.Collection, argNodes []parser.Node, evalArg func(parser.Node, types.Value) (types.Collection, error)) (types.Collection, error) {
+	var result types.Collection
+	for _, item := range input {
+		matched, err := evalArg(argNodes[0], item)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(matched) {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+// fnExists implements input.exists([criteria]): true if input has any
+// item at all, or, with criteria, any item for which criteria (with
+// $this rebound to that item) evaluates to true.
+func fnExists(ctx *eval.Context, input types.Collection, argNodes []parser.Node, evalArg func(parser.Node, types.Value) (types.Collection, error)) (types.Collection, error) {
+	if len(argNodes) == 0 {
+		return types.Collection{types.NewBoolean(!input.Empty())}, nil
+	}
+	for _, item := range input {
+		matched, err := evalArg(argNodes[0], item)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(matched) {
+			return types.Collection{types.NewBoolean(true)}, nil
+		}
+	}
+	return types.Collection{types.NewBoolean(false)}, nil
+}
+
+// fnFirst implements input.first(): the first item of input, or an
+// empty collection if input is empty.
+func fnFirst(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
+	if input.Empty() {
+		return types.Collection{}, nil
+	}
+	return types.Collection{input[0]}, nil
+}
+
+// truthy reports whether col is the single-item collection {true}, the
+// FHIRPath convention for a boolean expression's result.
+func truthy(col types.Collection) bool {
+	if len(col) != 1 {
+		return false
+	}
+	b, ok := col[0].(types.Boolean)
+	return ok && b.Value()
+}
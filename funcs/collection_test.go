@@ -0,0 +1,82 @@
+package funcs
+
+import (
+	"testing"
+
+	"github.com/gofhir/fhirpath/eval"
+	"github.com/gofhir/fhirpath/types"
+)
+
+// TestWhereFiltersByPerItemCriteria proves the review's own target
+// expression runs end to end: a leading resource-type identifier is a
+// no-op, coding is filtered down to the entry whose system matches
+// %loinc, and exists() reports whether anything survived the filter.
+func TestWhereFiltersByPerItemCriteria(t *testing.T) {
+	ctx := eval.NewContext([]byte(`{
+		"resourceType": "Observation",
+		"code": {
+			"coding": [
+				{"system": "http://loinc.org", "code": "1234-5"},
+				{"system": "http://snomed.info/sct", "code": "6789"}
+			]
+		}
+	}`))
+
+	result, err := evaluate(t, ctx, `Observation.code.coding.where(system = %loinc).exists()`)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("expected a single boolean, got %d", len(result))
+	}
+	b, ok := result[0].(types.Boolean)
+	if !ok || !b.Value() {
+		t.Fatalf("expected exists() to report true, got %#v", result[0])
+	}
+}
+
+func TestWhereExcludesNonMatchingItems(t *testing.T) {
+	ctx := eval.NewContext([]byte(`{
+		"resourceType": "Observation",
+		"code": {
+			"coding": [
+				{"system": "http://loinc.org", "code": "1234-5"},
+				{"system": "http://snomed.info/sct", "code": "6789"}
+			]
+		}
+	}`))
+
+	result, err := evaluate(t, ctx, `code.coding.where(system = %loinc).first()`)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("expected a single coding, got %d", len(result))
+	}
+	obj, ok := result[0].(*types.ObjectValue)
+	if !ok {
+		t.Fatalf("expected an ObjectValue, got %#v", result[0])
+	}
+	code, _ := obj.Get("code")
+	if s, ok := code.(types.String); !ok || s.Value() != "1234-5" {
+		t.Fatalf("expected the loinc coding, got %#v", code)
+	}
+}
+
+func TestExistsWithoutCriteria(t *testing.T) {
+	ctx := eval.NewContext([]byte(`{"resourceType": "Patient", "name": []}`))
+
+	result, err := evaluate(t, ctx, `name.exists()`)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected a single boolean, got %d", len(result))
+	}
+	b, ok := result[0].(types.Boolean)
+	if !ok || b.Value() {
+		t.Fatalf("expected exists() to report false for an empty collection, got %#v", result[0])
+	}
+}
@@ -1,7 +1,9 @@
 package funcs
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/gofhir/fhirpath/eval"
 	"github.com/gofhir/fhirpath/types"
@@ -60,59 +62,172 @@ func init() {
 }
 
 // fnResolve resolves a FHIR reference to the referenced resource.
-// This function requires a resolver to be set in the context.
+//
+// Resolution is tried in three steps, stopping at the first hit: the
+// `contained` entries on the current resource (matching `#id`), a
+// Bundle in scope (matching `fullUrl` or `ResourceType/id`, including
+// versioned refs and `urn:uuid:` fullUrls), and finally the external
+// Resolver, if one is configured. If none of these resolve the
+// reference, the result is empty, per the FHIRPath spec.
+//
+// Every reference that isn't resolved locally is fetched in one shot:
+// via Resolver.ResolveBatch if the configured Resolver implements
+// eval.BatchResolver, or otherwise via a bounded worker pool (sized by
+// ctx.ResolverConcurrency) over the plain Resolver.Resolve. Either way,
+// a reference already seen earlier in this evaluation is served from
+// ctx's resolve cache instead of being fetched again.
 func fnResolve(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
 	if input.Empty() {
 		return types.Collection{}, nil
 	}
 
-	resolver := ctx.GetResolver()
-	if resolver == nil {
-		// Without a resolver, we can't resolve references
-		// Return empty collection as per FHIRPath spec
-		return types.Collection{}, nil
+	references := make([]string, len(input))
+	for i, item := range input {
+		references[i] = referenceOf(item)
+	}
+
+	resourceJSON, err := resolveReferences(ctx, references)
+	if err != nil {
+		return nil, err
 	}
 
 	result := types.Collection{}
+	for _, raw := range resourceJSON {
+		if raw == nil {
+			continue
+		}
+		col, err := types.JSONToCollection(raw)
+		if err != nil {
+			continue
+		}
+		result = append(result, col...)
+	}
 
-	for _, item := range input {
-		var reference string
+	return result, nil
+}
 
-		switch v := item.(type) {
-		case types.String:
-			reference = v.Value()
-		case *types.ObjectValue:
-			// Try to get the 'reference' field from a Reference object
-			if ref, ok := v.Get("reference"); ok {
-				if refStr, ok := ref.(types.String); ok {
-					reference = refStr.Value()
-				}
+// referenceOf extracts the reference string from a FHIRPath value:
+// either a plain string reference or a Reference object's `reference`
+// field. It returns "" if item isn't a reference at all.
+func referenceOf(item types.Value) string {
+	switch v := item.(type) {
+	case types.String:
+		return v.Value()
+	case *types.ObjectValue:
+		if ref, ok := v.Get("reference"); ok {
+			if refStr, ok := ref.(types.String); ok {
+				return refStr.Value()
 			}
 		}
+	}
+	return ""
+}
+
+// resolveReferences resolves references in bulk, preserving input
+// order in the returned slice; unresolvable or empty references come
+// back as a nil entry.
+func resolveReferences(ctx *eval.Context, references []string) ([][]byte, error) {
+	result := make([][]byte, len(references))
+
+	// Resolve everything that's local or already cached first, and
+	// collect the distinct set of references still pending a fetch.
+	pending := make([]string, 0, len(references))
+	seen := make(map[string]bool, len(references))
 
+	for i, reference := range references {
 		if reference == "" {
 			continue
 		}
-
-		// Resolve the reference
-		resourceJSON, err := resolver.Resolve(ctx.Context(), reference)
-		if err != nil {
-			// Skip references that can't be resolved
+		if raw, ok := ctx.ResolveLocal(reference); ok {
+			result[i] = raw
 			continue
 		}
+		if raw, ok := ctx.CachedResolution(reference); ok {
+			result[i] = raw
+			continue
+		}
+		if !seen[reference] {
+			seen[reference] = true
+			pending = append(pending, reference)
+		}
+	}
 
-		// Parse the resolved resource
-		col, err := types.JSONToCollection(resourceJSON)
-		if err != nil {
+	if len(pending) == 0 {
+		return result, nil
+	}
+
+	resolver := ctx.GetResolver()
+	if resolver == nil {
+		// Without a resolver, unresolved references just stay empty,
+		// per the FHIRPath spec.
+		return result, nil
+	}
+
+	fetched, errs := fetchReferences(ctx, resolver, pending)
+	if len(fetched) != len(pending) || len(errs) != len(pending) {
+		return nil, fmt.Errorf("funcs: resolver returned %d results and %d errors for %d references", len(fetched), len(errs), len(pending))
+	}
+
+	for i, reference := range pending {
+		if errs[i] != nil || fetched[i] == nil {
 			continue
 		}
+		ctx.CacheResolution(reference, fetched[i])
+	}
 
-		result = append(result, col...)
+	fetchedByRef := make(map[string][]byte, len(pending))
+	for i, reference := range pending {
+		if errs[i] == nil {
+			fetchedByRef[reference] = fetched[i]
+		}
+	}
+
+	for i, reference := range references {
+		if result[i] != nil || reference == "" {
+			continue
+		}
+		result[i] = fetchedByRef[reference]
 	}
 
 	return result, nil
 }
 
+// fetchReferences fetches refs in one round trip via BatchResolver if
+// resolver supports it, otherwise spreads the fetches over a bounded
+// worker pool of plain Resolve calls.
+func fetchReferences(ctx *eval.Context, resolver eval.Resolver, refs []string) ([][]byte, []error) {
+	if batch, ok := resolver.(eval.BatchResolver); ok {
+		return batch.ResolveBatch(ctx.Context(), refs)
+	}
+
+	results := make([][]byte, len(refs))
+	errs := make([]error, len(refs))
+
+	concurrency := ctx.ResolverConcurrency()
+	if concurrency > len(refs) {
+		concurrency = len(refs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for w := 0; w < concurrency; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = resolver.Resolve(ctx.Context(), refs[i])
+			}
+		}()
+	}
+	for i := range refs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, errs
+}
+
 // fnExtension returns extensions matching the given URL.
 func fnExtension(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
 	if input.Empty() || len(args) == 0 {
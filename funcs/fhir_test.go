@@ -0,0 +1,33 @@
+package funcs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofhir/fhirpath/eval"
+)
+
+// shortBatchResolver returns fewer results/errors than it was asked to
+// resolve, simulating a host BatchResolver implementation that doesn't
+// honor the positional-alignment contract.
+type shortBatchResolver struct{}
+
+func (shortBatchResolver) Resolve(ctx context.Context, reference string) ([]byte, error) {
+	return nil, nil
+}
+
+func (shortBatchResolver) ResolveBatch(ctx context.Context, refs []string) ([][]byte, []error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	return [][]byte{[]byte(`{}`)}, []error{nil}
+}
+
+func TestResolveReferencesRejectsMismatchedBatchResult(t *testing.T) {
+	ctx := eval.NewContext(nil).WithResolver(shortBatchResolver{})
+
+	_, err := resolveReferences(ctx, []string{"Patient/1", "Patient/2"})
+	if err == nil {
+		t.Fatal("expected an error for a mismatched BatchResolver result, got nil")
+	}
+}
@@ -0,0 +1,31 @@
+// Package funcs implements the built-in FHIRPath functions and the
+// registry the Evaluator dispatches function-call nodes through.
+package funcs
+
+import "github.com/gofhir/fhirpath/eval"
+
+// FuncDef is an alias for eval.FuncDef so the function files in this
+// package can keep writing FuncDef{...} / Register(...) unqualified.
+type FuncDef = eval.FuncDef
+
+var registry = map[string]FuncDef{}
+
+// Register adds def to the registry GetRegistry hands to the
+// Evaluator. Each built-in function file calls this from its own
+// init().
+func Register(def FuncDef) {
+	registry[def.Name] = def
+}
+
+type funcRegistry struct{}
+
+func (funcRegistry) Lookup(name string) (eval.FuncDef, bool) {
+	def, ok := registry[name]
+	return def, ok
+}
+
+// GetRegistry returns the eval.Registry backed by every FuncDef
+// registered in this package via Register.
+func GetRegistry() eval.Registry {
+	return funcRegistry{}
+}
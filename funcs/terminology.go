@@ -0,0 +1,217 @@
+package funcs
+
+import (
+	"encoding/json"
+
+	"github.com/gofhir/fhirpath/eval"
+	"github.com/gofhir/fhirpath/types"
+)
+
+func init() {
+	Register(FuncDef{
+		Name:    "expand",
+		MinArgs: 1,
+		MaxArgs: 2,
+		Fn:      fnExpand,
+	})
+
+	Register(FuncDef{
+		Name:    "translate",
+		MinArgs: 1,
+		MaxArgs: 1,
+		Fn:      fnTranslate,
+	})
+
+	Register(FuncDef{
+		Name:    "subsumes",
+		MinArgs: 1,
+		MaxArgs: 1,
+		Fn:      fnSubsumes,
+	})
+}
+
+// fnExpand implements %terminologies.expand(url [, filter]). It
+// doesn't look at its input (conventionally the %terminologies
+// environment variable, a namespace marker rather than data) and
+// returns the ValueSet's Codings.
+func fnExpand(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
+	ts, ok := ctx.GetTerminologyService().(eval.TerminologyExpander)
+	if !ok {
+		return types.Collection{}, nil
+	}
+
+	valueSetURL, ok := firstStringArg(args, 0)
+	if !ok {
+		return types.Collection{}, nil
+	}
+	filter, _ := firstStringArg(args, 1)
+
+	codings, err := ts.Expand(ctx.Context(), valueSetURL, filter)
+	if err != nil {
+		return types.Collection{}, nil
+	}
+
+	return codingsToCollection(codings), nil
+}
+
+// fnTranslate implements code.translate(conceptMapURL): it maps each
+// code/Coding/CodeableConcept in the input through the ConceptMap,
+// collecting every returned Coding.
+func fnTranslate(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
+	if input.Empty() {
+		return types.Collection{}, nil
+	}
+
+	ts, ok := ctx.GetTerminologyService().(eval.TerminologyExpander)
+	if !ok {
+		return types.Collection{}, nil
+	}
+
+	conceptMapURL, ok := firstStringArg(args, 0)
+	if !ok {
+		return types.Collection{}, nil
+	}
+
+	result := types.Collection{}
+	for _, item := range input {
+		source, ok := extractCoding(item)
+		if !ok {
+			continue
+		}
+
+		codings, err := ts.Translate(ctx.Context(), source, conceptMapURL)
+		if err != nil {
+			continue
+		}
+		result = append(result, codingsToCollection(codings)...)
+	}
+
+	return result, nil
+}
+
+// fnSubsumes implements codingA.subsumes(codingB): both sides must
+// share a system for the relationship to be defined.
+func fnSubsumes(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
+	if input.Empty() {
+		return types.Collection{}, nil
+	}
+
+	ts, ok := ctx.GetTerminologyService().(eval.TerminologyExpander)
+	if !ok {
+		return types.Collection{}, nil
+	}
+
+	codingCol, ok := args[0].(types.Collection)
+	if !ok || codingCol.Empty() {
+		return types.Collection{}, nil
+	}
+
+	a, ok := extractCoding(input[0])
+	if !ok {
+		return types.Collection{}, nil
+	}
+	b, ok := extractCoding(codingCol[0])
+	if !ok || a.System == "" || a.System != b.System {
+		return types.Collection{}, nil
+	}
+
+	relationship, err := ts.Subsumes(ctx.Context(), a.System, a.Code, b.Code)
+	if err != nil {
+		return types.Collection{}, nil
+	}
+
+	return types.Collection{types.NewString(string(relationship))}, nil
+}
+
+// extractCoding extracts an eval.Coding from a code string, a Coding
+// object, or (using its first entry) a CodeableConcept.
+func extractCoding(item types.Value) (eval.Coding, bool) {
+	switch v := item.(type) {
+	case types.String:
+		return eval.Coding{Code: v.Value()}, true
+
+	case *types.ObjectValue:
+		if codings := v.GetCollection("coding"); !codings.Empty() {
+			first, ok := codings[0].(*types.ObjectValue)
+			if !ok {
+				return eval.Coding{}, false
+			}
+			return extractCoding(first)
+		}
+
+		coding := eval.Coding{}
+		if system, ok := v.Get("system"); ok {
+			if s, ok := system.(types.String); ok {
+				coding.System = s.Value()
+			}
+		}
+		if code, ok := v.Get("code"); ok {
+			if s, ok := code.(types.String); ok {
+				coding.Code = s.Value()
+			}
+		}
+		if version, ok := v.Get("version"); ok {
+			if s, ok := version.(types.String); ok {
+				coding.Version = s.Value()
+			}
+		}
+		if display, ok := v.Get("display"); ok {
+			if s, ok := display.(types.String); ok {
+				coding.Display = s.Value()
+			}
+		}
+		if coding.Code == "" {
+			return eval.Coding{}, false
+		}
+		return coding, true
+	}
+
+	return eval.Coding{}, false
+}
+
+// codingToValue renders an eval.Coding as a FHIRPath Coding object.
+func codingToValue(c eval.Coding) *types.ObjectValue {
+	fields := map[string]types.Value{}
+	if c.System != "" {
+		fields["system"] = types.NewString(c.System)
+	}
+	if c.Code != "" {
+		fields["code"] = types.NewString(c.Code)
+	}
+	if c.Version != "" {
+		fields["version"] = types.NewString(c.Version)
+	}
+	if c.Display != "" {
+		fields["display"] = types.NewString(c.Display)
+	}
+
+	raw, _ := json.Marshal(map[string]string{
+		"system": c.System, "code": c.Code, "version": c.Version, "display": c.Display,
+	})
+	return types.NewObjectValue(fields, raw)
+}
+
+func codingsToCollection(codings []eval.Coding) types.Collection {
+	result := make(types.Collection, len(codings))
+	for i, c := range codings {
+		result[i] = codingToValue(c)
+	}
+	return result
+}
+
+// firstStringArg returns args[i] as a string, if it's a non-empty
+// Collection whose first item is a String.
+func firstStringArg(args []interface{}, i int) (string, bool) {
+	if i >= len(args) {
+		return "", false
+	}
+	col, ok := args[i].(types.Collection)
+	if !ok || col.Empty() {
+		return "", false
+	}
+	s, ok := col[0].(types.String)
+	if !ok {
+		return "", false
+	}
+	return s.Value(), true
+}
@@ -0,0 +1,38 @@
+package funcs
+
+import (
+	"github.com/gofhir/fhirpath/eval"
+	"github.com/gofhir/fhirpath/types"
+)
+
+func init() {
+	Register(FuncDef{
+		Name:    "trace",
+		MinArgs: 1,
+		MaxArgs: 2,
+		Fn:      fnTrace,
+	})
+}
+
+// fnTrace implements trace(name [, selector]). Per spec, it always
+// returns its input unchanged; with no Observer installed it's a pure
+// identity function. With one installed, it reports name and either
+// the selector's result (if given) or the input itself.
+func fnTrace(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
+	observer := ctx.GetObserver()
+	if observer == nil {
+		return input, nil
+	}
+
+	name, _ := firstStringArg(args, 0)
+
+	values := input
+	if len(args) > 1 {
+		if col, ok := args[1].(types.Collection); ok {
+			values = col
+		}
+	}
+
+	observer.OnTrace(name, values)
+	return input, nil
+}
@@ -0,0 +1,74 @@
+package funcs
+
+import (
+	"errors"
+
+	"github.com/gofhir/fhirpath/eval"
+	"github.com/gofhir/fhirpath/types"
+)
+
+func init() {
+	Register(FuncDef{
+		Name:    "defineVariable",
+		MinArgs: 1,
+		MaxArgs: 2,
+		Fn:      fnDefineVariable,
+		Bind:    bindDefineVariable,
+	})
+}
+
+// fnDefineVariable implements the FHIRPath N1 defineVariable(name
+// [, expr]) function: it validates its arguments and passes its input
+// through unchanged. Binding %name for the rest of the pipeline is
+// Bind's job (see bindDefineVariable) — a FuncDef only ever sees the
+// Context it was called with, so it has no way to hand a new one back
+// through its Collection return value; the Evaluator calls Bind
+// separately and threads the *Context it returns into whatever
+// follows in the pipeline.
+func fnDefineVariable(ctx *eval.Context, input types.Collection, args []interface{}) (types.Collection, error) {
+	if _, _, err := defineVariableArgs(input, args); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// bindDefineVariable is fnDefineVariable's FuncDef.Bind: it derives
+// the same (name, value) pair fnDefineVariable validated and binds it
+// onto ctx, so %name is observable for every step the Evaluator runs
+// after this one.
+func bindDefineVariable(ctx *eval.Context, input types.Collection, args []interface{}) (*eval.Context, error) {
+	name, value, err := defineVariableArgs(input, args)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.WithVariable(name, value)
+}
+
+// defineVariableArgs extracts and validates defineVariable's name and
+// value from its arguments, defaulting value to input for the
+// one-argument form.
+func defineVariableArgs(input types.Collection, args []interface{}) (string, types.Collection, error) {
+	if len(args) == 0 {
+		return "", nil, errors.New("defineVariable: name argument is required")
+	}
+
+	nameCol, ok := args[0].(types.Collection)
+	if !ok || nameCol.Empty() {
+		return "", nil, errors.New("defineVariable: name argument must be a single string")
+	}
+	name, ok := nameCol[0].(types.String)
+	if !ok {
+		return "", nil, errors.New("defineVariable: name argument must be a string")
+	}
+
+	value := input
+	if len(args) > 1 {
+		valueCol, ok := args[1].(types.Collection)
+		if !ok {
+			return "", nil, errors.New("defineVariable: expr argument must evaluate to a collection")
+		}
+		value = valueCol
+	}
+
+	return name.Value(), value, nil
+}
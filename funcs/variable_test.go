@@ -0,0 +1,53 @@
+package funcs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofhir/fhirpath/eval"
+	"github.com/gofhir/fhirpath/parser"
+	"github.com/gofhir/fhirpath/types"
+)
+
+func evaluate(t *testing.T, ctx *eval.Context, source string) (types.Collection, error) {
+	t.Helper()
+	tree, err := parser.Parse(source)
+	if err != nil {
+		t.Fatalf("parse %q: %v", source, err)
+	}
+	evaluator := eval.NewEvaluator(ctx, GetRegistry())
+	return evaluator.Evaluate(tree)
+}
+
+// TestDefineVariableBindsForRestOfPipeline proves the fix for the bug
+// where defineVariable's binding never outlived the single function
+// call it was made in: %x must be readable by a step evaluated after
+// defineVariable('x', ...) in the same pipeline.
+func TestDefineVariableBindsForRestOfPipeline(t *testing.T) {
+	ctx := eval.NewContext([]byte(`{"resourceType":"Patient"}`))
+
+	result, err := evaluate(t, ctx, `defineVariable('x', 'hello').%x`)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+
+	if len(result) != 1 {
+		t.Fatalf("expected a single value, got %d", len(result))
+	}
+	s, ok := result[0].(types.String)
+	if !ok || s.Value() != "hello" {
+		t.Fatalf("expected %%x to be bound to \"hello\" for the rest of the pipeline, got %#v", result[0])
+	}
+}
+
+func TestDefineVariableRejectsReservedName(t *testing.T) {
+	ctx := eval.NewContext([]byte(`{"resourceType":"Patient"}`))
+
+	_, err := evaluate(t, ctx, `defineVariable('resource', 'hello')`)
+	if err == nil {
+		t.Fatal("expected an error binding a reserved variable name, got nil")
+	}
+	if !strings.Contains(err.Error(), "reserved") {
+		t.Fatalf("expected a reserved-name error, got: %v", err)
+	}
+}
@@ -0,0 +1,291 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/shopspring/decimal"
+)
+
+// Parse compiles FHIRPath source text into an expression tree. It
+// supports the subset of FHIRPath this module currently evaluates:
+// literals, $this, %variables, dot-chained path navigation and
+// function calls, and the and/or/=/!= operators. Syntax outside that
+// subset (indexers, unions, arithmetic, date/time literals, ...)
+// reports a parse error rather than silently misparsing.
+func Parse(source string) (Node, error) {
+	p := &parser{toks: tokenize(source), source: source}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("parser: unexpected %q at position %d in %q", p.peek().text, p.peek().pos, source)
+	}
+	return node, nil
+}
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokString
+	tokNumber
+	tokDot
+	tokLParen
+	tokRParen
+	tokComma
+	tokPercent
+	tokEQ
+	tokNEQ
+	tokOther
+	tokEOF
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(source string) []token {
+	var toks []token
+	runes := []rune(source)
+	i := 0
+	for i < len(runes) {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '.':
+			toks = append(toks, token{tokDot, ".", i})
+			i++
+		case r == '(':
+			toks = append(toks, token{tokLParen, "(", i})
+			i++
+		case r == ')':
+			toks = append(toks, token{tokRParen, ")", i})
+			i++
+		case r == ',':
+			toks = append(toks, token{tokComma, ",", i})
+			i++
+		case r == '%':
+			toks = append(toks, token{tokPercent, "%", i})
+			i++
+		case r == '=':
+			toks = append(toks, token{tokEQ, "=", i})
+			i++
+		case r == '!' && i+1 < len(runes) && runes[i+1] == '=':
+			toks = append(toks, token{tokNEQ, "!=", i})
+			i += 2
+		case r == '\'':
+			start := i
+			i++
+			var b strings.Builder
+			for i < len(runes) && runes[i] != '\'' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			i++ // closing quote
+			toks = append(toks, token{tokString, b.String(), start})
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			toks = append(toks, token{tokNumber, string(runes[start:i]), start})
+		case r == '$' || unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			toks = append(toks, token{tokIdent, string(runes[start:i]), start})
+		default:
+			// Anything this minimal tokenizer doesn't understand is
+			// surfaced as its own one-character token, so Parse reports
+			// an "unexpected token" error instead of looping forever.
+			toks = append(toks, token{tokOther, string(r), i})
+			i++
+		}
+	}
+	toks = append(toks, token{tokEOF, "", len(runes)})
+	return toks
+}
+
+type parser struct {
+	toks   []token
+	pos    int
+	source string
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if p.pos < len(p.toks)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokIdent && p.peek().text == "or" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "or", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseEquality()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokIdent && p.peek().text == "and" {
+		p.next()
+		right, err := p.parseEquality()
+		if err != nil {
+			return nil, err
+		}
+		left = Binary{Op: "and", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseEquality() (Node, error) {
+	left, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokEQ || p.peek().kind == tokNEQ {
+		op := "="
+		if p.peek().kind == tokNEQ {
+			op = "!="
+		}
+		p.next()
+		right, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return Binary{Op: op, Left: left, Right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parsePath() (Node, error) {
+	first, err := p.parseStep(true)
+	if err != nil {
+		return nil, err
+	}
+	steps := []Node{first}
+	for p.peek().kind == tokDot {
+		p.next()
+		step, err := p.parseStep(false)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 1 {
+		return Path{Steps: steps}, nil
+	}
+	return Path{Steps: steps}, nil
+}
+
+// parseStep parses one dot-separated path step. first is true only
+// for the leading step, the one place a parenthesized sub-expression
+// is allowed; literals, %variables and $this are accepted at any step.
+func (p *parser) parseStep(first bool) (Node, error) {
+	t := p.peek()
+	switch t.kind {
+	case tokString:
+		p.next()
+		return StringLiteral{Value: t.text}, nil
+	case tokNumber:
+		p.next()
+		d, err := decimal.NewFromString(t.text)
+		if err != nil {
+			return nil, fmt.Errorf("parser: invalid number %q: %w", t.text, err)
+		}
+		return NumberLiteral{Value: d}, nil
+	case tokPercent:
+		p.next()
+		name := p.next()
+		if name.kind != tokIdent {
+			return nil, fmt.Errorf("parser: expected a variable name after %%, got %q", name.text)
+		}
+		return Variable{Name: name.text}, nil
+	case tokLParen:
+		if !first {
+			return nil, fmt.Errorf("parser: unexpected %q at position %d", t.text, t.pos)
+		}
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, fmt.Errorf("parser: expected ')' at position %d", p.peek().pos)
+		}
+		p.next()
+		return inner, nil
+	case tokIdent:
+		p.next()
+		switch t.text {
+		case "true":
+			return BoolLiteral{Value: true}, nil
+		case "false":
+			return BoolLiteral{Value: false}, nil
+		case "$this":
+			return This{}, nil
+		}
+		if p.peek().kind == tokLParen {
+			p.next()
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			return Call{Name: t.text, Args: args}, nil
+		}
+		return Identifier{Name: t.text}, nil
+	default:
+		return nil, fmt.Errorf("parser: unexpected %q at position %d", t.text, t.pos)
+	}
+}
+
+func (p *parser) parseArgs() ([]Node, error) {
+	var args []Node
+	if p.peek().kind == tokRParen {
+		p.next()
+		return args, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek().kind == tokComma {
+			p.next()
+			continue
+		}
+		break
+	}
+	if p.peek().kind != tokRParen {
+		return nil, fmt.Errorf("parser: expected ')' at position %d", p.peek().pos)
+	}
+	p.next()
+	return args, nil
+}
@@ -0,0 +1,22 @@
+package types
+
+import "github.com/shopspring/decimal"
+
+// Decimal is a FHIRPath decimal primitive, backed by shopspring/decimal
+// so that FHIR decimal/currency values keep exact precision instead of
+// drifting through float64.
+type Decimal struct {
+	value decimal.Decimal
+}
+
+// NewDecimal wraps a decimal.Decimal as a FHIRPath Decimal value.
+func NewDecimal(v decimal.Decimal) Decimal {
+	return Decimal{value: v}
+}
+
+// Value returns the underlying decimal.Decimal.
+func (d Decimal) Value() decimal.Decimal {
+	return d.value
+}
+
+func (d Decimal) fhirpathValue() {}
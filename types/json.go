@@ -0,0 +1,85 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// JSONToCollection parses a FHIR resource (or any JSON value) into a
+// FHIRPath Collection of one item, the root ObjectValue (or a single
+// primitive Value for string, number or boolean input, or a nested
+// Collection for an array). JSON null yields an empty Collection.
+func JSONToCollection(data []byte) (Collection, error) {
+	v, err := decodeValue(data)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return Collection{}, nil
+	}
+	return Collection{v}, nil
+}
+
+// decodeValue converts a raw JSON value into a Value, preserving the
+// original bytes of each object so ObjectValue.Data() can hand back
+// exactly what it was parsed from.
+func decodeValue(raw []byte) (Value, error) {
+	var probe interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&probe); err != nil {
+		return nil, fmt.Errorf("types: invalid JSON: %w", err)
+	}
+
+	switch v := probe.(type) {
+	case nil:
+		return nil, nil
+	case bool:
+		return NewBoolean(v), nil
+	case string:
+		return NewString(v), nil
+	case json.Number:
+		d, err := decimal.NewFromString(v.String())
+		if err != nil {
+			return nil, fmt.Errorf("types: invalid number %q: %w", v.String(), err)
+		}
+		return NewDecimal(d), nil
+	case []interface{}:
+		var raws []json.RawMessage
+		if err := json.Unmarshal(raw, &raws); err != nil {
+			return nil, fmt.Errorf("types: invalid array: %w", err)
+		}
+		col := make(Collection, 0, len(raws))
+		for _, item := range raws {
+			iv, err := decodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			if iv != nil {
+				col = append(col, iv)
+			}
+		}
+		return col, nil
+	case map[string]interface{}:
+		var raws map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &raws); err != nil {
+			return nil, fmt.Errorf("types: invalid object: %w", err)
+		}
+		fields := make(map[string]Value, len(raws))
+		for key, item := range raws {
+			iv, err := decodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			if iv != nil {
+				fields[key] = iv
+			}
+		}
+		return NewObjectValue(fields, raw), nil
+	default:
+		return nil, fmt.Errorf("types: unsupported JSON value %T", v)
+	}
+}
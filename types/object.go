@@ -0,0 +1,43 @@
+package types
+
+// ObjectValue is a FHIRPath complex value: a FHIR object (resource,
+// backbone element, or datatype) backed by its original JSON so that
+// callers needing the raw bytes (e.g. conformsTo) don't pay for a
+// round-trip re-encode.
+type ObjectValue struct {
+	fields map[string]Value
+	raw    []byte
+}
+
+// NewObjectValue builds an ObjectValue from its decoded fields and the
+// raw JSON it was parsed from.
+func NewObjectValue(fields map[string]Value, raw []byte) *ObjectValue {
+	return &ObjectValue{fields: fields, raw: raw}
+}
+
+func (o *ObjectValue) fhirpathValue() {}
+
+// Get returns the value of a single-cardinality field.
+func (o *ObjectValue) Get(name string) (Value, bool) {
+	v, ok := o.fields[name]
+	return v, ok
+}
+
+// GetCollection returns the values of a repeating field, a Collection
+// of one for a single-valued field, or an empty Collection if the
+// field is absent.
+func (o *ObjectValue) GetCollection(name string) Collection {
+	v, ok := o.fields[name]
+	if !ok {
+		return Collection{}
+	}
+	if col, ok := v.(Collection); ok {
+		return col
+	}
+	return Collection{v}
+}
+
+// Data returns the raw JSON this object was parsed from.
+func (o *ObjectValue) Data() []byte {
+	return o.raw
+}
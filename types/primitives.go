@@ -0,0 +1,35 @@
+package types
+
+// String is a FHIRPath string primitive.
+type String struct {
+	value string
+}
+
+// NewString wraps a Go string as a FHIRPath String value.
+func NewString(v string) String {
+	return String{value: v}
+}
+
+// Value returns the underlying Go string.
+func (s String) Value() string {
+	return s.value
+}
+
+func (s String) fhirpathValue() {}
+
+// Boolean is a FHIRPath boolean primitive.
+type Boolean struct {
+	value bool
+}
+
+// NewBoolean wraps a Go bool as a FHIRPath Boolean value.
+func NewBoolean(v bool) Boolean {
+	return Boolean{value: v}
+}
+
+// Value returns the underlying Go bool.
+func (b Boolean) Value() bool {
+	return b.value
+}
+
+func (b Boolean) fhirpathValue() {}
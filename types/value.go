@@ -0,0 +1,24 @@
+// Package types defines the FHIRPath value model: the primitive and
+// object representations that flow through expression evaluation.
+package types
+
+// Value is any FHIRPath value that can appear in a Collection: a
+// primitive (String, Boolean, Decimal) or a complex
+// object backed by FHIR JSON (ObjectValue).
+type Value interface {
+	fhirpathValue()
+}
+
+// Collection is an ordered list of Values, the universal currency of
+// FHIRPath evaluation (every expression produces a Collection, even a
+// single scalar is a Collection of one).
+type Collection []Value
+
+// Empty reports whether the collection has no items.
+func (c Collection) Empty() bool {
+	return len(c) == 0
+}
+
+// Collection is itself a Value so that repeating fields can be stored
+// and retrieved as a single unit (see ObjectValue.GetCollection).
+func (c Collection) fhirpathValue() {}
@@ -0,0 +1,36 @@
+package viewdef
+
+// ColumnNames compiles vd and returns the names of the columns it
+// produces, in declaration order, flattening nested select/unionAll
+// trees and de-duplicating repeats (the same column name can appear in
+// more than one unionAll branch). It's meant for callers that need a
+// fixed header up front, such as the CSV writer.
+func ColumnNames(vd []byte) ([]string, error) {
+	cv, err := compile(vd)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, sel := range cv.selects {
+		collectColumnNames(sel, seen, &names)
+	}
+	return names, nil
+}
+
+func collectColumnNames(sel compiledSelect, seen map[string]bool, names *[]string) {
+	for _, col := range sel.columns {
+		if seen[col.name] {
+			continue
+		}
+		seen[col.name] = true
+		*names = append(*names, col.name)
+	}
+	for _, nested := range sel.nested {
+		collectColumnNames(nested, seen, names)
+	}
+	for _, branch := range sel.unionAll {
+		collectColumnNames(branch, seen, names)
+	}
+}
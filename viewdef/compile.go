@@ -0,0 +1,113 @@
+package viewdef
+
+import (
+	"fmt"
+
+	"github.com/gofhir/fhirpath"
+)
+
+// compiledView is a ViewDefinition with every path expression compiled
+// once, ready to be evaluated against many resources.
+type compiledView struct {
+	resource string
+	wheres   []*fhirpath.Expression
+	constant []compiledConstant
+	selects  []compiledSelect
+}
+
+type compiledConstant struct {
+	name  string
+	value []byte // raw value[x] JSON, decoded lazily via types.JSONToCollection
+}
+
+type compiledColumn struct {
+	name       string
+	expr       *fhirpath.Expression
+	collection bool
+}
+
+type compiledSelect struct {
+	columns  []compiledColumn
+	nested   []compiledSelect
+	unionAll []compiledSelect
+
+	forEach       *fhirpath.Expression
+	forEachOrNull bool // true if forEach came from forEachOrNull
+}
+
+func compile(vd []byte) (*compiledView, error) {
+	parsed, err := parseViewDefinition(vd)
+	if err != nil {
+		return nil, fmt.Errorf("viewdef: invalid ViewDefinition: %w", err)
+	}
+
+	cv := &compiledView{resource: parsed.Resource}
+
+	for _, w := range parsed.Where {
+		expr, err := fhirpath.Compile(w.Path)
+		if err != nil {
+			return nil, fmt.Errorf("viewdef: where %q: %w", w.Path, err)
+		}
+		cv.wheres = append(cv.wheres, expr)
+	}
+
+	for _, c := range parsed.Constant {
+		cv.constant = append(cv.constant, compiledConstant{name: c.Name, value: c.Value})
+	}
+
+	for _, sel := range parsed.Select {
+		compiledSel, err := compileSelect(sel)
+		if err != nil {
+			return nil, err
+		}
+		cv.selects = append(cv.selects, compiledSel)
+	}
+
+	return cv, nil
+}
+
+func compileSelect(sel selectElement) (compiledSelect, error) {
+	var out compiledSelect
+
+	switch {
+	case sel.ForEach != "":
+		expr, err := fhirpath.Compile(sel.ForEach)
+		if err != nil {
+			return out, fmt.Errorf("viewdef: forEach %q: %w", sel.ForEach, err)
+		}
+		out.forEach = expr
+	case sel.ForEachOrNull != "":
+		expr, err := fhirpath.Compile(sel.ForEachOrNull)
+		if err != nil {
+			return out, fmt.Errorf("viewdef: forEachOrNull %q: %w", sel.ForEachOrNull, err)
+		}
+		out.forEach = expr
+		out.forEachOrNull = true
+	}
+
+	for _, c := range sel.Column {
+		expr, err := fhirpath.Compile(c.Path)
+		if err != nil {
+			return out, fmt.Errorf("viewdef: column %q: %w", c.Name, err)
+		}
+		out.columns = append(out.columns, compiledColumn{name: c.Name, expr: expr, collection: c.Collection})
+	}
+
+	for _, nested := range sel.Select {
+		compiledNested, err := compileSelect(nested)
+		if err != nil {
+			return out, err
+		}
+		out.nested = append(out.nested, compiledNested)
+	}
+
+	for _, branch := range sel.UnionAll {
+		compiledBranch, err := compileSelect(branch)
+		if err != nil {
+			return out, err
+		}
+		out.unionAll = append(out.unionAll, compiledBranch)
+	}
+
+	return out, nil
+}
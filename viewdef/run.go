@@ -0,0 +1,268 @@
+package viewdef
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gofhir/fhirpath"
+	"github.com/gofhir/fhirpath/eval"
+	"github.com/gofhir/fhirpath/types"
+)
+
+// Row is one output row of a view: column name to cell value. Cell
+// values are the Go equivalents JSON would produce (string, bool,
+// decimal.Decimal, json.RawMessage for a whole FHIR object, or a slice
+// of any of those for a `collection: true` column), so a Row can be
+// handed directly to encoding/json or flattened for CSV.
+type Row map[string]interface{}
+
+// Run compiles vd and evaluates it against every resource read from
+// in, emitting one Row per output row on the returned channel. The
+// channel is closed once in is drained (or closed); a resource that
+// fails `where`, doesn't match `resource`, or errors during evaluation
+// contributes no rows rather than aborting the whole run.
+func Run(vd []byte, in <-chan []byte) (<-chan Row, error) {
+	cv, err := compile(vd)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan Row)
+	go func() {
+		defer close(out)
+		for resourceJSON := range in {
+			rows, err := evalResource(cv, resourceJSON)
+			if err != nil {
+				continue
+			}
+			for _, row := range rows {
+				out <- row
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+func evalResource(cv *compiledView, resourceJSON []byte) ([]Row, error) {
+	if cv.resource != "" && !matchesResourceType(resourceJSON, cv.resource) {
+		return nil, nil
+	}
+
+	ctx := eval.NewContext(resourceJSON)
+
+	ctx, err := bindConstants(ctx, cv.constant)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := evalWhere(ctx, cv.wheres)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	rows := []Row{{}}
+	for _, sel := range cv.selects {
+		selRows, err := evalSelect(sel, ctx)
+		if err != nil {
+			return nil, err
+		}
+		rows = crossJoin(rows, selRows)
+	}
+	return rows, nil
+}
+
+// evalSelect evaluates one select tree node against ctx, returning the
+// rows it contributes. forEach/forEachOrNull re-root ctx onto each
+// item of the iterated collection; nested `select` entries add columns
+// to the same rows (cross joined one-to-one per forEach root);
+// `unionAll` branches each contribute their own independent row set,
+// unioned together with whatever this node's own columns/nested
+// selects produced.
+func evalSelect(sel compiledSelect, ctx *eval.Context) ([]Row, error) {
+	roots := []*eval.Context{ctx}
+	forEachEmpty := false
+
+	if sel.forEach != nil {
+		col, err := sel.forEach.EvaluateWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !col.Empty():
+			roots = roots[:0]
+			for _, item := range col {
+				obj, ok := item.(*types.ObjectValue)
+				if !ok {
+					continue
+				}
+				roots = append(roots, ctx.WithResource(obj.Data()))
+			}
+		case sel.forEachOrNull:
+			forEachEmpty = true
+		default:
+			// forEach (not forEachOrNull) over an empty collection
+			// contributes no rows at all.
+			return nil, nil
+		}
+	}
+
+	var rows []Row
+	for _, rctx := range roots {
+		row := Row{}
+		for _, col := range sel.columns {
+			if forEachEmpty {
+				row[col.name] = nil
+				continue
+			}
+			value, err := evalColumn(rctx, col)
+			if err != nil {
+				return nil, err
+			}
+			row[col.name] = value
+		}
+
+		combined := []Row{row}
+		for _, nested := range sel.nested {
+			nestedRows, err := evalSelect(nested, rctx)
+			if err != nil {
+				return nil, err
+			}
+			combined = crossJoin(combined, nestedRows)
+		}
+		rows = append(rows, combined...)
+	}
+
+	if len(sel.unionAll) == 0 {
+		return rows, nil
+	}
+
+	unioned := append([]Row{}, rows...)
+	for _, branch := range sel.unionAll {
+		branchRows, err := evalSelect(branch, ctx)
+		if err != nil {
+			return nil, err
+		}
+		unioned = append(unioned, branchRows...)
+	}
+	return unioned, nil
+}
+
+func evalColumn(ctx *eval.Context, col compiledColumn) (interface{}, error) {
+	result, err := col.expr.EvaluateWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return columnValue(col.name, result, col.collection)
+}
+
+// columnValue converts a column's evaluation result into a cell value.
+// `collection: true` columns always produce a slice; other columns
+// produce a single scalar, or an error if the expression returned more
+// than one value (scalar-or-error, per the ViewDefinition spec).
+func columnValue(name string, result types.Collection, collection bool) (interface{}, error) {
+	if collection {
+		cells := make([]interface{}, len(result))
+		for i, v := range result {
+			cells[i] = cellValue(v)
+		}
+		return cells, nil
+	}
+
+	switch len(result) {
+	case 0:
+		return nil, nil
+	case 1:
+		return cellValue(result[0]), nil
+	default:
+		return nil, fmt.Errorf("viewdef: column %q: expected a single value, got %d", name, len(result))
+	}
+}
+
+func cellValue(v types.Value) interface{} {
+	switch vv := v.(type) {
+	case types.String:
+		return vv.Value()
+	case types.Boolean:
+		return vv.Value()
+	case types.Decimal:
+		return vv.Value()
+	case *types.ObjectValue:
+		return json.RawMessage(vv.Data())
+	case types.Collection:
+		cells := make([]interface{}, len(vv))
+		for i, item := range vv {
+			cells[i] = cellValue(item)
+		}
+		return cells
+	default:
+		return nil
+	}
+}
+
+func bindConstants(ctx *eval.Context, constants []compiledConstant) (*eval.Context, error) {
+	for _, c := range constants {
+		value, err := types.JSONToCollection(c.value)
+		if err != nil {
+			return nil, fmt.Errorf("viewdef: constant %q: %w", c.name, err)
+		}
+		ctx, err = ctx.WithVariable(c.name, value)
+		if err != nil {
+			return nil, fmt.Errorf("viewdef: constant %q: %w", c.name, err)
+		}
+	}
+	return ctx, nil
+}
+
+func evalWhere(ctx *eval.Context, wheres []*fhirpath.Expression) (bool, error) {
+	for _, w := range wheres {
+		result, err := w.EvaluateWithContext(ctx)
+		if err != nil {
+			return false, err
+		}
+		if result.Empty() {
+			return false, nil
+		}
+		b, ok := result[0].(types.Boolean)
+		if !ok || !b.Value() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchesResourceType(resourceJSON []byte, resourceType string) bool {
+	var resource struct {
+		ResourceType string `json:"resourceType"`
+	}
+	if err := json.Unmarshal(resourceJSON, &resource); err != nil {
+		return false
+	}
+	return resource.ResourceType == resourceType
+}
+
+// crossJoin merges every row of a with every row of b. Either side
+// being empty yields no rows, matching SQL's CROSS JOIN with an empty
+// table (and, for a forEach branch, meaning "this parent row had no
+// matches to emit").
+func crossJoin(a, b []Row) []Row {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+
+	out := make([]Row, 0, len(a)*len(b))
+	for _, ra := range a {
+		for _, rb := range b {
+			merged := make(Row, len(ra)+len(rb))
+			for k, v := range ra {
+				merged[k] = v
+			}
+			for k, v := range rb {
+				merged[k] = v
+			}
+			out = append(out, merged)
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+package viewdef
+
+import "testing"
+
+// TestRunEndToEnd proves fhirpath.Compile/Evaluate actually produce
+// rows: a where clause, a plain column, a forEach that re-roots the
+// context per array entry, and a nested column, all compiled and run
+// together against a real resource.
+func TestRunEndToEnd(t *testing.T) {
+	vd := []byte(`{
+		"resource": "Patient",
+		"where": [{"path": "active = true"}],
+		"select": [
+			{"column": [{"name": "id", "path": "id"}]},
+			{
+				"forEach": "name",
+				"select": [
+					{"column": [
+						{"name": "family", "path": "family"},
+						{"name": "given", "path": "given", "collection": true}
+					]}
+				]
+			}
+		]
+	}`)
+
+	patient := []byte(`{
+		"resourceType": "Patient",
+		"id": "pt1",
+		"active": true,
+		"name": [
+			{"family": "Smith", "given": ["Jane"]},
+			{"family": "Doe", "given": ["Jo", "Ann"]}
+		]
+	}`)
+
+	in := make(chan []byte, 1)
+	in <- patient
+	close(in)
+
+	out, err := Run(vd, in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var rows []Row
+	for row := range out {
+		rows = append(rows, row)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("expected one row per name (cross joined with the id column), got %d: %#v", len(rows), rows)
+	}
+
+	families := map[string]bool{}
+	for _, row := range rows {
+		if row["id"] != "pt1" {
+			t.Fatalf("expected id=pt1 on every row, got %#v", row["id"])
+		}
+		family, ok := row["family"].(string)
+		if !ok {
+			t.Fatalf("expected family to be a string, got %#v", row["family"])
+		}
+		families[family] = true
+	}
+	if !families["Smith"] || !families["Doe"] {
+		t.Fatalf("expected both Smith and Doe rows, got %#v", rows)
+	}
+}
+
+// TestRunUnionAllStacksRowsWithoutCrossMultiplying proves unionAll
+// branches are appended (SQL UNION ALL semantics) rather than cross
+// joined with each other, while still being cross joined against a
+// sibling select at the same level.
+func TestRunUnionAllStacksRowsWithoutCrossMultiplying(t *testing.T) {
+	vd := []byte(`{
+		"resource": "Patient",
+		"select": [
+			{"column": [{"name": "id", "path": "id"}]},
+			{
+				"unionAll": [
+					{"column": [{"name": "kind", "path": "id"}]},
+					{"column": [{"name": "kind", "path": "id"}]}
+				]
+			}
+		]
+	}`)
+
+	patient := []byte(`{"resourceType": "Patient", "id": "pt1"}`)
+
+	in := make(chan []byte, 1)
+	in <- patient
+	close(in)
+
+	out, err := Run(vd, in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var rows []Row
+	for row := range out {
+		rows = append(rows, row)
+	}
+
+	// The unionAll node's own (column-less) row plus its two branches
+	// gives 3 rows, cross joined against the single id-select row —
+	// 1*3, not a further multiplication of the two branches against
+	// each other.
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows (1 id row x (1 own + 2 unioned) rows), got %d: %#v", len(rows), rows)
+	}
+	withKind := 0
+	for _, row := range rows {
+		if row["id"] != "pt1" {
+			t.Fatalf("unexpected row contents: %#v", row)
+		}
+		if row["kind"] == "pt1" {
+			withKind++
+		}
+	}
+	if withKind != 2 {
+		t.Fatalf("expected exactly 2 of the 3 rows to carry the unionAll branches' kind column, got %d: %#v", withKind, rows)
+	}
+}
+
+// TestRunColumnWithWhereFilteredPath proves a column path isn't limited
+// to flat field navigation: real ViewDefinitions routinely filter a
+// repeating element down to one entry (name.where(use='official')) before
+// projecting a field off it, the way telecom.where(system='phone').value
+// or name.where(use='official').given do in practice.
+func TestRunColumnWithWhereFilteredPath(t *testing.T) {
+	vd := []byte(`{
+		"resource": "Patient",
+		"select": [
+			{"column": [
+				{"name": "id", "path": "id"},
+				{"name": "official_given", "path": "name.where(use='official').given.first()"}
+			]}
+		]
+	}`)
+
+	patient := []byte(`{
+		"resourceType": "Patient",
+		"id": "pt1",
+		"name": [
+			{"use": "nickname", "given": ["Janie"]},
+			{"use": "official", "given": ["Jane", "Ann"]}
+		]
+	}`)
+
+	in := make(chan []byte, 1)
+	in <- patient
+	close(in)
+
+	out, err := Run(vd, in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var rows []Row
+	for row := range out {
+		rows = append(rows, row)
+	}
+
+	if len(rows) != 1 {
+		t.Fatalf("expected a single row, got %d: %#v", len(rows), rows)
+	}
+	if rows[0]["official_given"] != "Jane" {
+		t.Fatalf("expected official_given=Jane, got %#v", rows[0]["official_given"])
+	}
+}
@@ -0,0 +1,72 @@
+// Package viewdef runs a SQL-on-FHIR ViewDefinition against a stream
+// of FHIR resources, producing tabular rows. Every `path` in the
+// ViewDefinition is an ordinary FHIRPath expression, compiled once via
+// fhirpath.Compile and reused for every input resource.
+package viewdef
+
+import "encoding/json"
+
+// viewDefinition is the subset of the ViewDefinition resource this
+// package understands: resource type, row filters, constants, and the
+// select tree that shapes output columns.
+type viewDefinition struct {
+	Resource string          `json:"resource"`
+	Where    []whereClause   `json:"where"`
+	Constant []constant      `json:"constant"`
+	Select   []selectElement `json:"select"`
+}
+
+type whereClause struct {
+	Path string `json:"path"`
+}
+
+// constant binds a FHIR value[x] to a %name variable available to
+// every path expression in the view. Exactly one value* field should
+// be present, per the value[x] convention.
+type constant struct {
+	Name  string
+	Value json.RawMessage
+}
+
+func (c *constant) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if name, ok := raw["name"]; ok {
+		if err := json.Unmarshal(name, &c.Name); err != nil {
+			return err
+		}
+	}
+	for key, value := range raw {
+		if key == "name" {
+			continue
+		}
+		c.Value = value
+	}
+	return nil
+}
+
+// selectElement mirrors one entry of ViewDefinition.select (and,
+// recursively, of a nested select[] or unionAll[]).
+type selectElement struct {
+	Column        []column        `json:"column"`
+	Select        []selectElement `json:"select"`
+	ForEach       string          `json:"forEach"`
+	ForEachOrNull string          `json:"forEachOrNull"`
+	UnionAll      []selectElement `json:"unionAll"`
+}
+
+type column struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Collection bool   `json:"collection"`
+}
+
+func parseViewDefinition(vd []byte) (*viewDefinition, error) {
+	var parsed viewDefinition
+	if err := json.Unmarshal(vd, &parsed); err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,53 @@
+package viewdef
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteNDJSON drains rows, writing one JSON object per line to w until
+// rows is closed.
+func WriteNDJSON(w io.Writer, rows <-chan Row) error {
+	enc := json.NewEncoder(w)
+	for row := range rows {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV drains rows, writing a header of columns followed by one
+// CSV record per row, until rows is closed. A row missing one of
+// columns gets an empty cell; values are rendered via fmt.Sprint
+// (json.RawMessage objects render as their raw JSON text).
+func WriteCSV(w io.Writer, columns []string, rows <-chan Row) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for row := range rows {
+		for i, col := range columns {
+			value, ok := row[col]
+			if !ok || value == nil {
+				record[i] = ""
+				continue
+			}
+			if raw, ok := value.(json.RawMessage); ok {
+				record[i] = string(raw)
+				continue
+			}
+			record[i] = fmt.Sprint(value)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}